@@ -0,0 +1,146 @@
+package affected
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/dag"
+)
+
+// Target a target that must be rebuilt because of a change
+type Target struct {
+	Key string `json:"key"`
+
+	// Files the changed files that belong to the target, empty if it is only affected through a dependency
+	Files []string `json:"files,omitempty"`
+
+	// Root is true if no other affected target depends on this target
+	// Running every root target rebuilds every affected target
+	Root bool `json:"root"`
+
+	RawTarget ark.RawTarget `json:"-"`
+}
+
+// Changed returns true if the files of the target changed rather than one of its dependencies
+func (t Target) Changed() bool {
+	return len(t.Files) > 0
+}
+
+// Targets returns every target whose build file or source files are in changedFiles
+// and every target that transitively depends on one of them, sorted by key
+// changedFiles must be absolute paths
+func Targets(graph *dag.AcyclicGraph, changedFiles []string) ([]Target, error) {
+	changed := make(map[string]bool, len(changedFiles))
+	for _, file := range changedFiles {
+		changed[filepath.Clean(file)] = true
+	}
+
+	affected := make(map[string]*Target)
+	add := func(rawTarget ark.RawTarget) *Target {
+		if target, ok := affected[rawTarget.Key()]; ok {
+			return target
+		}
+		target := &Target{
+			Key:       rawTarget.Key(),
+			RawTarget: rawTarget,
+		}
+		affected[target.Key] = target
+		return target
+	}
+
+	for _, vertex := range graph.Vertices() {
+		rawTarget, err := toRawTarget(vertex)
+		if err != nil {
+			return nil, err
+		}
+
+		files := changedFilesOf(rawTarget, changed)
+		if len(files) == 0 {
+			continue
+		}
+
+		add(rawTarget).Files = files
+
+		// edges point from a target to its dependencies so the targets that depend on it are its descendents
+		dependents, err := graph.Descendents(vertex)
+		if err != nil {
+			return nil, err
+		}
+		for _, dependent := range dependents.List() {
+			dependentTarget, castErr := toRawTarget(dependent)
+			if castErr != nil {
+				return nil, castErr
+			}
+			add(dependentTarget)
+		}
+	}
+
+	targets := make([]Target, 0, len(affected))
+	for _, target := range affected {
+		target.Root = true
+		for _, dependent := range graph.UpEdges(target.RawTarget).List() {
+			dependentTarget, err := toRawTarget(dependent)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := affected[dependentTarget.Key()]; ok {
+				target.Root = false
+				break
+			}
+		}
+		targets = append(targets, *target)
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Key < targets[j].Key
+	})
+	return targets, nil
+}
+
+// Roots returns the keys of the affected targets that no other affected target depends on
+func Roots(targets []Target) []string {
+	var keys []string
+	for _, target := range targets {
+		if target.Root {
+			keys = append(keys, target.Key)
+		}
+	}
+	return keys
+}
+
+// Keys returns the keys of the affected targets
+func Keys(targets []Target) []string {
+	keys := make([]string, 0, len(targets))
+	for _, target := range targets {
+		keys = append(keys, target.Key)
+	}
+	return keys
+}
+
+func changedFilesOf(target ark.RawTarget, changed map[string]bool) []string {
+	var files []string
+	if changed[filepath.Clean(target.File)] {
+		files = append(files, target.File)
+	}
+	for _, file := range target.SourceFiles {
+		if changed[filepath.Clean(file)] {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// the graph may contain static copies or pointers to targets
+func toRawTarget(vertex dag.Vertex) (ark.RawTarget, error) {
+	switch t := vertex.(type) {
+	case ark.RawTarget:
+		return t, nil
+	case *ark.RawTarget:
+		return *t, nil
+	default:
+		return ark.RawTarget{}, errors.Errorf("%T is not type ark.RawTarget", vertex)
+	}
+}
@@ -0,0 +1,60 @@
+package affected
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/storage/graph"
+)
+
+func TestTargets(t *testing.T) {
+	newTarget := func(name string, sourceFiles ...string) ark.RawTarget {
+		return ark.RawTarget{
+			Name:        name,
+			Type:        "group",
+			File:        "/repo/" + name + "/build.ts",
+			Realm:       "/repo",
+			SourceFiles: sourceFiles,
+		}
+	}
+
+	base := newTarget("base", "/repo/base/base.go")
+	lib := newTarget("lib", "/repo/lib/lib.go")
+	app := newTarget("app", "/repo/app/main.go")
+	docs := newTarget("docs", "/repo/docs/README.md")
+
+	g, err := graph.FromTargetsAndEdges(
+		[]ark.RawTarget{base, lib, app, docs},
+		[]ark.GraphEdge{
+			{Src: lib.Key(), Dst: base.Key()},
+			{Src: app.Key(), Dst: lib.Key()},
+		},
+	)
+	require.NoError(t, err)
+
+	t.Run("should include reverse dependencies of changed targets", func(t *testing.T) {
+		targets, err := Targets(g, []string{"/repo/base/base.go", "/repo/unowned.txt"})
+		require.NoError(t, err)
+		require.Equal(t, []string{app.Key(), base.Key(), lib.Key()}, Keys(targets))
+		require.Equal(t, []string{app.Key()}, Roots(targets))
+
+		for _, target := range targets {
+			require.Equal(t, target.Key == base.Key(), target.Changed())
+		}
+	})
+
+	t.Run("should treat a changed build file as a change to its targets", func(t *testing.T) {
+		targets, err := Targets(g, []string{"/repo/docs/build.ts"})
+		require.NoError(t, err)
+		require.Equal(t, []string{docs.Key()}, Keys(targets))
+		require.Equal(t, []string{"/repo/docs/build.ts"}, targets[0].Files)
+	})
+
+	t.Run("should return nothing when no target changed", func(t *testing.T) {
+		targets, err := Targets(g, nil)
+		require.NoError(t, err)
+		require.Empty(t, targets)
+	})
+}
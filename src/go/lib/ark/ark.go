@@ -3,6 +3,7 @@ package ark
 import (
 	"context"
 	"hash"
+	"time"
 )
 
 // Hashable is an interface implemented by structs that can produce a deterministic hash of their properties
@@ -34,9 +35,27 @@ type Target interface {
 type Derivation struct {
 	Target   Target
 	Artifact Artifact
+
+	// Error is set when the derivation is published by a failed action
+	Error string `json:",omitempty"`
+
+	// StartedAt is the time the graph walker began processing the derivation
+	StartedAt time.Time
+
+	// Duration is the wall time spent processing the derivation
+	// It is set when the derivation is published by a cached, successful or failed action
+	Duration time.Duration `json:",omitempty"`
+
+	// Attempt is the number of times the action has been executed
+	// It is set when the derivation is published by an executed action
+	Attempt int `json:",omitempty"`
 }
 
 type Derivative struct {
 	RawTarget   RawTarget   `json:"Target"`
 	RawArtifact RawArtifact `json:"Artifact"`
+	Error       string      `json:",omitempty"`
+	StartedAt   time.Time
+	Duration    time.Duration `json:",omitempty"`
+	Attempt     int           `json:",omitempty"`
 }
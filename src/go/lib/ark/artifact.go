@@ -2,13 +2,16 @@ package ark
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/myfintech/ark/src/go/lib/ark/workspace"
+	"github.com/myfintech/ark/src/go/lib/xdgbase"
 
 	"github.com/myfintech/ark/src/go/lib/fs"
 
@@ -19,12 +22,16 @@ import (
 
 // RawArtifact represents the core representation of any artifact
 type RawArtifact struct {
-	Key                string                 `json:"key" mapstructure:"key"`
-	Hash               string                 `json:"hash" mapstructure:"hash"`
-	Type               string                 `json:"type" mapstructure:"type"`
-	Attributes         map[string]interface{} `json:"attributes" mapstructure:"attributes,remain"`
-	DependsOn          Ancestors              `json:"dependsOn" mapstructure:"dependsOn" hash:"-"`
-	RemoteCacheBaseURL string                 `json:"remote_cache_base_url" mapstructure:"remote_cache_base_url"`
+	Key                       string                 `json:"key" mapstructure:"key"`
+	Hash                      string                 `json:"hash" mapstructure:"hash"`
+	Type                      string                 `json:"type" mapstructure:"type"`
+	Attributes                map[string]interface{} `json:"attributes" mapstructure:"attributes,remain"`
+	DependsOn                 Ancestors              `json:"dependsOn" mapstructure:"dependsOn" hash:"-"`
+	Inputs                    *HashInputs            `json:"inputs,omitempty" mapstructure:"inputs" hash:"-"`
+	RemoteCacheBaseURL        string                 `json:"remote_cache_base_url" mapstructure:"remote_cache_base_url"`
+	RemoteCacheHeaders        map[string]string      `json:"-" mapstructure:"-"`
+	RemoteCachePublicKey      string                 `json:"-" mapstructure:"-"`
+	RemoteCachePrivateKeyPath string                 `json:"-" mapstructure:"-"`
 }
 
 // Artifact is an interface that defines the behavior of a resource that can be stored and fetched from a content addressable store
@@ -36,6 +43,15 @@ type Artifact interface {
 	Pull(ctx context.Context) error
 	MkCacheDir() (string, error)
 	WriteState() error
+	CaptureOutputs(dir string, outputs []string) error
+	RestoreOutputs(dir string, outputs []string) error
+}
+
+// Disposer an artifact that writes files which must not outlive the runs that use them
+// The graph walker retains the artifact before its action is executed and disposes of it once the walk of the run finished
+type Disposer interface {
+	Retain()
+	Dispose() error
 }
 
 // Key is a convenience struct to assist with directory creation
@@ -57,9 +73,18 @@ func ParseKey(key string) (Key, error) {
 	}, nil
 }
 
-// UseWorkspaceConfig injects the blob storage base URL into the raw artifact struct
+// UseWorkspaceConfig injects the blob storage base URL, remote cache headers and signing keys into the raw artifact struct
 func (r *RawArtifact) UseWorkspaceConfig(config workspace.Config) {
 	r.RemoteCacheBaseURL = config.RemoteCache.URL
+	r.RemoteCacheHeaders = config.RemoteCache.ExpandedHeaders()
+	r.RemoteCachePublicKey = config.RemoteCache.PublicKey
+	r.RemoteCachePrivateKeyPath = os.ExpandEnv(config.RemoteCache.PrivateKeyPath)
+}
+
+func (r RawArtifact) remoteCacheOptions() []cloudutils.BlobOption {
+	return []cloudutils.BlobOption{
+		cloudutils.WithHeaders(r.RemoteCacheHeaders),
+	}
 }
 
 // Cacheable always returns true as the default behavior should be to cache something
@@ -68,14 +93,16 @@ func (r RawArtifact) Cacheable() bool {
 }
 
 // RemotelyCached checks a remote blob store for the presence of an artifact
+// An artifact is never remotely cached if the workspace does not configure a remote cache
 func (r RawArtifact) RemotelyCached(ctx context.Context) (bool, error) {
 	if r.RemoteCacheBaseURL == "" {
-		return false, errors.New("a remote cache URL has not been provided in the workspace configuration")
+		return false, nil
 	}
-	return cloudutils.BlobCheck(ctx, r.RemoteCacheBaseURL, fmt.Sprintf("%s.tar.gz", r.Hash))
+	return cloudutils.BlobCheck(ctx, r.RemoteCacheBaseURL, fmt.Sprintf("%s.tar.gz", r.Hash), r.remoteCacheOptions()...)
 }
 
 // LocallyCached checks for the presence of a local artifact.json file
+// A cache hit refreshes the last access time of the artifact used for LRU eviction
 func (r RawArtifact) LocallyCached(_ context.Context) (bool, error) {
 	localCacheDir, err := r.CacheDirPath()
 	if err != nil {
@@ -83,10 +110,24 @@ func (r RawArtifact) LocallyCached(_ context.Context) (bool, error) {
 	}
 
 	if _, err = os.Stat(filepath.Join(localCacheDir, "artifact.json")); os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
 		return false, err
 	}
 
-	return true, err
+	return true, r.Touch()
+}
+
+// Touch updates the last access time of the local cache directory of the artifact
+func (r RawArtifact) Touch() error {
+	localCacheDir, err := r.CacheDirPath()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return os.Chtimes(localCacheDir, now, now)
 }
 
 // Push uploads an artifact to a remote blob store
@@ -95,7 +136,7 @@ func (r RawArtifact) Push(ctx context.Context) error {
 		return errors.New("a remote cache URL has not been provided in the workspace configuration")
 	}
 
-	writer, cleanup, err := cloudutils.NewBlobWriter(ctx, r.RemoteCacheBaseURL, fmt.Sprintf("%s.tar.gz", r.Hash))
+	writer, cleanup, err := cloudutils.NewBlobWriter(ctx, r.RemoteCacheBaseURL, fmt.Sprintf("%s.tar.gz", r.Hash), r.remoteCacheOptions()...)
 	defer func() {
 		if cleanup != nil {
 			cleanup()
@@ -110,10 +151,25 @@ func (r RawArtifact) Push(ctx context.Context) error {
 		return err
 	}
 
+	var privateKey ed25519.PrivateKey
+	if r.RemoteCachePrivateKeyPath != "" {
+		if privateKey, err = ReadPrivateKey(r.RemoteCachePrivateKeyPath); err != nil {
+			return err
+		}
+	}
+
+	if err = WriteArtifactManifest(r.Hash, localCacheDir, privateKey); err != nil {
+		return errors.Wrap(err, "failed to write artifact manifest")
+	}
+
 	if err = fs.GzipTar(localCacheDir, writer); err != nil {
 		return err
 	}
 
+	if committer, ok := writer.(cloudutils.Committer); ok {
+		return committer.Commit()
+	}
+
 	return nil
 }
 
@@ -123,7 +179,7 @@ func (r RawArtifact) Pull(ctx context.Context) error {
 		return errors.New("a remote cache URL has not been provided in the workspace configuration")
 	}
 
-	reader, cleanup, err := cloudutils.NewBlobReader(ctx, r.RemoteCacheBaseURL, fmt.Sprintf("%s.tar.gz", r.Hash))
+	reader, cleanup, err := cloudutils.NewBlobReader(ctx, r.RemoteCacheBaseURL, fmt.Sprintf("%s.tar.gz", r.Hash), r.remoteCacheOptions()...)
 	defer func() {
 		if cleanup != nil {
 			cleanup()
@@ -133,15 +189,44 @@ func (r RawArtifact) Pull(ctx context.Context) error {
 		return err
 	}
 
-	localCacheDir, err := r.MkCacheDir()
+	localCacheDir, err := r.CacheDirPath()
 	if err != nil {
 		return err
 	}
-	if err = fs.GzipUntar(localCacheDir, reader); err != nil {
+
+	// the artifact is extracted to a staging directory and only moved into the cache after verification
+	// this prevents a truncated or tampered artifact from ever being reported as locally cached
+	stagingDir := localCacheDir + ArtifactStagingSuffix
+	if err = os.RemoveAll(stagingDir); err != nil {
 		return err
 	}
+	if err = os.MkdirAll(stagingDir, 0755); err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(stagingDir)
+	}()
 
-	return nil
+	if err = fs.GzipUntar(stagingDir, reader); err != nil {
+		return errors.Wrapf(err, "failed to extract artifact %s", r.Key)
+	}
+
+	var publicKey ed25519.PublicKey
+	if r.RemoteCachePublicKey != "" {
+		if publicKey, err = ParsePublicKey(r.RemoteCachePublicKey); err != nil {
+			return err
+		}
+	}
+
+	if err = VerifyArtifactManifest(r.Hash, stagingDir, publicKey); err != nil {
+		return errors.Wrapf(err, "artifact %s failed verification", r.Key)
+	}
+
+	if err = os.RemoveAll(localCacheDir); err != nil {
+		return err
+	}
+
+	return os.Rename(stagingDir, localCacheDir)
 }
 
 // CacheDirPath returns the constructed name of the local filesystem path for local caching of an artifact
@@ -151,12 +236,26 @@ func (r RawArtifact) CacheDirPath() (string, error) {
 		return "", err
 	}
 
-	cacheDir, err := os.UserHomeDir()
+	cacheDir, err := ArtifactsDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, key.Name, r.Hash), nil
+}
+
+// ArtifactStagingSuffix is appended to the cache directory of an artifact to stage it while it is pulled
+const ArtifactStagingSuffix = ".pull"
+
+// ArtifactsDir returns the root of the local artifact cache
+// The location respects the XDG Base Directory Specification ($ARK_CACHE_HOME, $XDG_CACHE_HOME, $HOME/.cache/ark)
+func ArtifactsDir() (string, error) {
+	cacheDir, err := xdgbase.Dir("ark", xdgbase.CacheSuffix)
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(cacheDir, "ark", "artifacts", key.Name, r.Hash), nil
+	return filepath.Join(cacheDir, "artifacts"), nil
 }
 
 // MkCacheDir creates a location on disk for storing an artifact locally
@@ -0,0 +1,208 @@
+package ark
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/fs"
+)
+
+const (
+	// ManifestFileName the name of the file which records the digest of every file in an artifact
+	ManifestFileName = "manifest.json"
+
+	// ManifestSignatureFileName the name of the file which contains the ed25519 signature of the manifest
+	ManifestSignatureFileName = "manifest.sig"
+)
+
+// ArtifactManifest records the sha256 digest of every file in an artifact cache directory
+// The manifest is pushed with an artifact and verified when the artifact is pulled
+type ArtifactManifest struct {
+	Hash  string            `json:"hash"`
+	Files map[string]string `json:"files"`
+}
+
+// NewArtifactManifest computes the manifest of every file in dir, excluding the manifest and its signature
+func NewArtifactManifest(hash, dir string) (ArtifactManifest, error) {
+	manifest := ArtifactManifest{
+		Hash:  hash,
+		Files: make(map[string]string),
+	}
+
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		name := filepath.ToSlash(fs.TrimPrefix(file, dir))
+		if name == ManifestFileName || name == ManifestSignatureFileName {
+			return nil
+		}
+
+		digest, err := fs.HashFile(file, nil)
+		if err != nil {
+			return err
+		}
+
+		manifest.Files[name] = hex.EncodeToString(digest.Sum(nil))
+		return nil
+	})
+
+	return manifest, err
+}
+
+// Marshal returns a deterministic json encoding of the manifest that can be signed
+func (m ArtifactManifest) Marshal() ([]byte, error) {
+	// encoding/json sorts map keys which makes the output stable
+	return json.MarshalIndent(m, "", " ")
+}
+
+// Verify ensures dir contains exactly the files listed in the manifest with matching digests
+func (m ArtifactManifest) Verify(hash, dir string) error {
+	if m.Hash != hash {
+		return errors.Errorf("manifest was created for artifact %s not %s", m.Hash, hash)
+	}
+
+	actual, err := NewArtifactManifest(hash, dir)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for name, digest := range m.Files {
+		actualDigest, exists := actual.Files[name]
+		if !exists {
+			problems = append(problems, "missing "+name)
+			continue
+		}
+		if actualDigest != digest {
+			problems = append(problems, "digest mismatch "+name)
+		}
+	}
+
+	for name := range actual.Files {
+		if _, expected := m.Files[name]; !expected {
+			problems = append(problems, "unexpected "+name)
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.Errorf("artifact contents do not match its manifest: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// WriteArtifactManifest computes the manifest of dir and writes it to dir
+// If a private key is provided the signature of the manifest is written alongside it
+func WriteArtifactManifest(hash, dir string, privateKey ed25519.PrivateKey) error {
+	manifest, err := NewArtifactManifest(hash, dir)
+	if err != nil {
+		return err
+	}
+
+	data, err := manifest.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, ManifestFileName), data, 0644); err != nil {
+		return err
+	}
+
+	signaturePath := filepath.Join(dir, ManifestSignatureFileName)
+	if privateKey == nil {
+		// a stale signature from a previous push would fail verification
+		if err = os.Remove(signaturePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data))
+	return os.WriteFile(signaturePath, []byte(signature), 0644)
+}
+
+// VerifyArtifactManifest reads the manifest in dir and verifies the contents of dir against it
+// If a public key is provided the manifest must have a valid signature
+func VerifyArtifactManifest(hash, dir string, publicKey ed25519.PublicKey) error {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if os.IsNotExist(err) {
+		return errors.New("artifact does not contain a manifest")
+	}
+	if err != nil {
+		return err
+	}
+
+	if publicKey != nil {
+		encodedSignature, readErr := os.ReadFile(filepath.Join(dir, ManifestSignatureFileName))
+		if os.IsNotExist(readErr) {
+			return errors.New("artifact manifest is not signed")
+		}
+		if readErr != nil {
+			return readErr
+		}
+
+		signature, decodeErr := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encodedSignature)))
+		if decodeErr != nil {
+			return errors.Wrap(decodeErr, "failed to decode artifact manifest signature")
+		}
+
+		if !ed25519.Verify(publicKey, data, signature) {
+			return errors.New("artifact manifest signature is invalid")
+		}
+	}
+
+	var manifest ArtifactManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return errors.Wrap(err, "failed to decode artifact manifest")
+	}
+
+	return manifest.Verify(hash, dir)
+}
+
+// ParsePublicKey decodes a base64 encoded ed25519 public key
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode public key")
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, errors.Errorf("public key must be %d bytes", ed25519.PublicKeySize)
+	}
+	return key, nil
+}
+
+// ReadPrivateKey reads a base64 encoded ed25519 private key (or seed) from a file
+func ReadPrivateKey(file string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode private key %s", file)
+	}
+
+	switch len(key) {
+	case ed25519.PrivateKeySize:
+		return key, nil
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(key), nil
+	default:
+		return nil, errors.Errorf("private key %s must be %d or %d bytes", file, ed25519.PrivateKeySize, ed25519.SeedSize)
+	}
+}
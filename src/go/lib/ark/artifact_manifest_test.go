@@ -0,0 +1,72 @@
+package ark
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArtifactManifest(t *testing.T) {
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	testdata := filepath.Join(cwd, "testdata", "manifest")
+	require.NoError(t, os.MkdirAll(filepath.Join(testdata, "nested"), 0755))
+	defer func() {
+		_ = os.RemoveAll(testdata)
+	}()
+
+	require.NoError(t, os.WriteFile(filepath.Join(testdata, "artifact.json"), []byte(`{}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(testdata, "nested", "output.txt"), []byte("output"), 0644))
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("should verify an unmodified artifact", func(t *testing.T) {
+		require.NoError(t, WriteArtifactManifest("1234", testdata, privateKey))
+		require.FileExists(t, filepath.Join(testdata, ManifestSignatureFileName))
+		require.NoError(t, VerifyArtifactManifest("1234", testdata, publicKey))
+	})
+
+	t.Run("should reject a manifest for a different hash", func(t *testing.T) {
+		require.Error(t, VerifyArtifactManifest("5678", testdata, nil))
+	})
+
+	t.Run("should reject a signature from an unknown key", func(t *testing.T) {
+		otherPublicKey, _, keyErr := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, keyErr)
+		require.Error(t, VerifyArtifactManifest("1234", testdata, otherPublicKey))
+	})
+
+	t.Run("should reject modified, missing and unexpected files", func(t *testing.T) {
+		output := filepath.Join(testdata, "nested", "output.txt")
+		require.NoError(t, os.WriteFile(output, []byte("tampered"), 0644))
+		require.Error(t, VerifyArtifactManifest("1234", testdata, nil))
+
+		require.NoError(t, os.Remove(output))
+		require.Error(t, VerifyArtifactManifest("1234", testdata, nil))
+
+		require.NoError(t, os.WriteFile(output, []byte("output"), 0644))
+		require.NoError(t, VerifyArtifactManifest("1234", testdata, nil))
+
+		require.NoError(t, os.WriteFile(filepath.Join(testdata, "extra.txt"), []byte("extra"), 0644))
+		require.Error(t, VerifyArtifactManifest("1234", testdata, nil))
+	})
+
+	t.Run("should parse encoded keys", func(t *testing.T) {
+		parsed, keyErr := ParsePublicKey(base64.StdEncoding.EncodeToString(publicKey))
+		require.NoError(t, keyErr)
+		require.Equal(t, publicKey, parsed)
+
+		keyFile := filepath.Join(testdata, "key")
+		require.NoError(t, os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(privateKey.Seed())), 0600))
+		readKey, keyErr := ReadPrivateKey(keyFile)
+		require.NoError(t, keyErr)
+		require.Equal(t, privateKey, readKey)
+	})
+}
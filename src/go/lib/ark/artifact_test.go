@@ -57,7 +57,7 @@ func TestRawArtifact(t *testing.T) {
 
 	require.NoError(t, os.RemoveAll(cacheDir))
 	exists, err = mockRawArtifact.LocallyCached(context.TODO())
-	require.Error(t, err)
+	require.NoError(t, err, "a cache miss is not an error")
 	require.False(t, exists)
 
 	require.NoError(t, mockRawArtifact.Pull(context.TODO()))
@@ -68,4 +68,10 @@ func TestRawArtifact(t *testing.T) {
 	require.True(t, exists)
 
 	require.NoError(t, cloudutils.DeleteBlob(context.TODO(), mockRawArtifact.RemoteCacheBaseURL, fmt.Sprintf("%s.tar.gz", mockRawArtifact.Hash)))
+
+	withoutRemote := mockRawArtifact
+	withoutRemote.RemoteCacheBaseURL = ""
+	exists, err = withoutRemote.RemotelyCached(context.TODO())
+	require.NoError(t, err, "a workspace without a remote cache is not an error")
+	require.False(t, exists)
 }
@@ -39,6 +39,13 @@ type Broker interface {
 	Receiver
 }
 
+// Replayer an interface that describes a system that retains the messages of a subject
+// so a late subscriber receives them from the start before it receives new messages
+// The channel is closed once the subject ended or the context is canceled
+type Replayer interface {
+	Replay(ctx context.Context, topic RouteKey, subject RouteKey) (<-chan Envelope, error)
+}
+
 // Caller is an interface that describes a system that expects to make a synchronous request to another system
 type Caller interface {
 	Request(topic RouteKey, message Message) (Envelope, error)
@@ -30,6 +30,12 @@ var (
 	// GraphWalkerActionCachedType
 	GraphWalkerActionCachedType = cqrs.WithType(GraphWalkerActionCached)
 
+	// GraphWalkerActionSkipped
+	GraphWalkerActionSkipped = topics.GraphWalkerEvents.With("action.skipped")
+
+	// GraphWalkerActionSkippedType
+	GraphWalkerActionSkippedType = cqrs.WithType(GraphWalkerActionSkipped)
+
 	// GraphWalkerActionStarted
 	GraphWalkerActionStarted = topics.GraphWalkerEvents.With("action.started")
 
@@ -42,6 +48,30 @@ var (
 	// GraphWalkerActionSuccessType
 	GraphWalkerActionSuccessType = cqrs.WithType(GraphWalkerActionSuccess)
 
+	// GraphWalkerActionRetry
+	GraphWalkerActionRetry = topics.GraphWalkerEvents.With("action.retry")
+
+	// GraphWalkerActionRetryType
+	GraphWalkerActionRetryType = cqrs.WithType(GraphWalkerActionRetry)
+
+	// GraphWalkerActionFailureAllowed
+	GraphWalkerActionFailureAllowed = topics.GraphWalkerEvents.With("action.failure_allowed")
+
+	// GraphWalkerActionFailureAllowedType
+	GraphWalkerActionFailureAllowedType = cqrs.WithType(GraphWalkerActionFailureAllowed)
+
+	// GraphWalkerActionAwaitingShared
+	GraphWalkerActionAwaitingShared = topics.GraphWalkerEvents.With("action.awaiting_shared")
+
+	// GraphWalkerActionAwaitingSharedType
+	GraphWalkerActionAwaitingSharedType = cqrs.WithType(GraphWalkerActionAwaitingShared)
+
+	// GraphWalkerActionBlocked
+	GraphWalkerActionBlocked = topics.GraphWalkerEvents.With("action.blocked")
+
+	// GraphWalkerActionBlockedType
+	GraphWalkerActionBlockedType = cqrs.WithType(GraphWalkerActionBlocked)
+
 	// GraphWalkerArtifactPushStarted
 	GraphWalkerArtifactPushStarted = topics.GraphWalkerEvents.With("artifact.push.started")
 
@@ -12,7 +12,15 @@ type GraphRunnerExecuteCommand struct {
 	PushAfterBuild bool     `json:"pushAfterBuild"`
 	ForceBuild     bool     `json:"forceBuild"`
 	SkipFilters    []string `json:"skipFilters"`
+	SkipMode       string   `json:"skipMode"`
 	MaxConcurrency int      `json:"maxConcurrency"`
+	Labels         []string `json:"labels"`
+	ExcludeLabels  []string `json:"excludeLabels"`
+	KeepGoing      bool     `json:"keepGoing"`
+
+	// RunID the id of the run chosen by the client so it can subscribe to the events of the run before it starts
+	// the server generates an id if it is empty
+	RunID string `json:"runId"`
 }
 
 // GraphRunnerExecuteCommandResponse is a struct that represent the payload for the command handler response
@@ -0,0 +1,361 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs"
+)
+
+// scopedSubjectPrefix the first token of the NATS subjects retained messages are published to
+// A message of a retained topic with the subject S is published to scoped.S.<topic>
+const scopedSubjectPrefix = "scoped"
+
+// scopedStreamPrefix the prefix of the name of the stream that retains the messages of a subject
+const scopedStreamPrefix = "scoped_"
+
+// JetStreamOptions configures which messages the JetStreamBroker retains and for how long
+type JetStreamOptions struct {
+	// Topics the topics whose messages are retained in a stream per message subject
+	Topics []cqrs.RouteKey
+
+	// Storage the storage of the streams, the zero value stores them in files
+	Storage nats.StorageType
+
+	// MaxAge the duration messages are retained for, messages are retained forever when zero
+	// The stream of a subject is deleted once no message was published to it for MaxAge
+	MaxAge time.Duration
+
+	// FinalTypes the message types that end a subject, the stream of the subject is deleted MaxAge after one is published
+	// Streams of subjects that never ended are deleted when the broker is created after they were idle for MaxAge
+	FinalTypes []cqrs.RouteKey
+}
+
+// JetStreamBroker a NATS broker that retains the messages of a set of topics in a JetStream stream per message subject
+// The subject of a graph event is the subscription ID of its run, so every run gets its own stream that can be replayed
+// Messages of other topics and messages without a subject are published with core NATS and are not retained
+type JetStreamBroker struct {
+	Broker
+	js      nats.JetStreamContext
+	options JetStreamOptions
+	streams sync.Map
+
+	// publishErr the first acknowledgement of an asynchronous publish that failed since the last call to Publish
+	publishMutex sync.Mutex
+	publishErr   error
+}
+
+// maxPendingAcks the number of asynchronous publishes that may await their acknowledgement before Publish blocks
+const maxPendingAcks = 256
+
+// closeTimeout the duration Close waits for the acknowledgements of pending publishes
+const closeTimeout = 5 * time.Second
+
+// Publish send a message, messages of retained topics are stored in the stream of their subject
+// Messages are stored asynchronously, an acknowledgement that failed is returned by the next call to Publish
+func (b *JetStreamBroker) Publish(topic cqrs.RouteKey, messages ...cqrs.Message) error {
+	if err := b.takePublishErr(); err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		subject := message.Subject()
+		if !b.retained(topic) || !validSubjectToken(subject) {
+			if err := b.Broker.Publish(topic, message); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+		if err = b.ensureStream(subject); err != nil {
+			return err
+		}
+		if _, err = b.js.PublishAsync(scopedSubject(subject, topic), data); err != nil {
+			return errors.Wrapf(err, "failed to publish %s to the stream of %s", message.Type(), subject)
+		}
+		if b.final(cqrs.RouteKey(message.Type())) {
+			b.expire(subject, b.options.MaxAge)
+		}
+	}
+	return nil
+}
+
+// Close waits for the acknowledgements of pending publishes before it closes the connection
+func (b *JetStreamBroker) Close() error {
+	select {
+	case <-b.js.PublishAsyncComplete():
+	case <-time.After(closeTimeout):
+	}
+	return b.Broker.Close()
+}
+
+func (b *JetStreamBroker) failPublish(err error) {
+	b.publishMutex.Lock()
+	defer b.publishMutex.Unlock()
+	if b.publishErr == nil {
+		b.publishErr = err
+	}
+}
+
+func (b *JetStreamBroker) takePublishErr() error {
+	b.publishMutex.Lock()
+	defer b.publishMutex.Unlock()
+	err := b.publishErr
+	b.publishErr = nil
+	return err
+}
+
+// Subscribe registers interest in the given topic and returns a channel to begin processing messages
+// Messages of the topic are received regardless of the subject they are retained for
+// The subscription will be canceled and interest will be removed from the topic when the context is canceled
+func (b *JetStreamBroker) Subscribe(
+	ctx context.Context,
+	topic cqrs.RouteKey,
+	_ *time.Duration,
+) (<-chan cqrs.Envelope, error) {
+	// the full wildcard already matches the scoped subjects
+	if topic.String() == ">" {
+		return b.subscribe(ctx, topic.String())
+	}
+	return b.subscribe(ctx, topic.String(), scopedSubject("*", topic))
+}
+
+// Replay returns every retained message of the topic with the given subject in the order they were published
+// followed by the messages published after the replay started
+// The topic may contain wildcards, topics that aren't retained are never replayed
+// A subject without a stream is followed from the time of the replay on
+// The channel is closed after a message of one of the FinalTypes was delivered or when the context is canceled
+func (b *JetStreamBroker) Replay(ctx context.Context, topic cqrs.RouteKey, subject cqrs.RouteKey) (<-chan cqrs.Envelope, error) {
+	if !validSubjectToken(subject.String()) {
+		return nil, errors.Errorf("%s is not a valid subject to replay", subject)
+	}
+
+	scoped := scopedSubject(subject.String(), topic)
+	replay := func(handler nats.MsgHandler) (*nats.Subscription, error) {
+		return b.js.Subscribe(
+			scoped,
+			handler,
+			nats.BindStream(scopedStreamName(subject.String())),
+			nats.OrderedConsumer(),
+			nats.DeliverAll(),
+		)
+	}
+
+	exists, err := b.streamExists(subject.String())
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return b.relay(ctx, replay)
+	}
+
+	// a subject without a stream has no retained messages, replaying it never creates a stream
+	// the stream is looked up again after the live subscription was created
+	// so a stream created in between is replayed instead of missing its first messages
+	liveCtx, cancelLive := context.WithCancel(ctx)
+	keepLive := false
+	defer func() {
+		if !keepLive {
+			cancelLive()
+		}
+	}()
+
+	live, err := b.relay(liveCtx, func(handler nats.MsgHandler) (*nats.Subscription, error) {
+		return b.nc.Subscribe(scoped, handler)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if exists, err = b.streamExists(subject.String()); err != nil {
+		return nil, err
+	}
+	if !exists {
+		keepLive = true
+		return live, nil
+	}
+	return b.relay(ctx, replay)
+}
+
+// streamExists returns true if the stream of a subject exists
+func (b *JetStreamBroker) streamExists(subject string) (bool, error) {
+	_, err := b.js.StreamInfo(scopedStreamName(subject))
+	if errors.Is(err, nats.ErrStreamNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to look up the stream of %s", subject)
+	}
+	return true, nil
+}
+
+// relay delivers the messages of a subscription to a channel
+// the subscription ends and the channel is closed after a message of one of the FinalTypes was delivered or when the context is canceled
+func (b *JetStreamBroker) relay(ctx context.Context, subscribe func(handler nats.MsgHandler) (*nats.Subscription, error)) (<-chan cqrs.Envelope, error) {
+	relayCtx, cancel := context.WithCancel(ctx)
+	stream := make(chan cqrs.Envelope)
+
+	var mutex sync.Mutex
+	closed := false
+	finish := func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if !closed {
+			closed = true
+			close(stream)
+		}
+	}
+
+	sub, err := subscribe(func(msg *nats.Msg) {
+		envelope := cqrs.NewEnvelope(cqrs.FromData(msg.Data))
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if closed {
+			return
+		}
+
+		// a subscriber that stopped reading must not block the subscription
+		select {
+		case stream <- envelope:
+		case <-relayCtx.Done():
+			return
+		}
+
+		if envelope.Error == nil && b.final(envelope.TypeKey()) {
+			closed = true
+			close(stream)
+			cancel()
+		}
+	})
+	if err != nil {
+		cancel()
+		finish()
+		return nil, errors.Wrap(err, "failed to subscribe")
+	}
+
+	go func() {
+		<-relayCtx.Done()
+		_ = sub.Unsubscribe()
+		finish()
+	}()
+
+	return stream, nil
+}
+
+func (b *JetStreamBroker) final(messageType cqrs.RouteKey) bool {
+	for _, final := range b.options.FinalTypes {
+		if final == messageType {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *JetStreamBroker) retained(topic cqrs.RouteKey) bool {
+	for _, retained := range b.options.Topics {
+		if retained == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureStream creates the stream of a subject once, adding a stream with an identical configuration is a no-op
+func (b *JetStreamBroker) ensureStream(subject string) error {
+	if _, ok := b.streams.Load(subject); ok {
+		return nil
+	}
+
+	if _, err := b.js.AddStream(&nats.StreamConfig{
+		Name:     scopedStreamName(subject),
+		Subjects: []string{scopedSubject(subject, ">")},
+		Storage:  b.options.Storage,
+		MaxAge:   b.options.MaxAge,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to create the stream of %s", subject)
+	}
+
+	b.streams.Store(subject, struct{}{})
+	return nil
+}
+
+// expire deletes the stream of a subject once it was idle for the duration
+func (b *JetStreamBroker) expire(subject string, after time.Duration) {
+	if b.options.MaxAge <= 0 {
+		return
+	}
+	time.AfterFunc(after, func() {
+		b.deleteIdleStream(subject)
+	})
+}
+
+// deleteIdleStream deletes the stream of a subject if nothing was published to it for MaxAge
+// a stream that received a message since is checked again once it could be idle for MaxAge
+func (b *JetStreamBroker) deleteIdleStream(subject string) {
+	info, err := b.js.StreamInfo(scopedStreamName(subject))
+	if err != nil {
+		return
+	}
+
+	if idle := time.Since(info.State.LastTime); idle < b.options.MaxAge {
+		b.expire(subject, b.options.MaxAge-idle)
+		return
+	}
+
+	b.streams.Delete(subject)
+	_ = b.js.DeleteStream(scopedStreamName(subject))
+}
+
+// NewJetStreamBroker creates a new NATS broker that retains messages in JetStream
+// The streams left by a previous broker are deleted once they were idle for MaxAge
+// The NATS server must have JetStream enabled, see JetStreamServerOptions
+func NewJetStreamBroker(nc *nats.Conn, options JetStreamOptions) (*JetStreamBroker, error) {
+	broker := &JetStreamBroker{
+		Broker:  Broker{nc: nc},
+		options: options,
+	}
+
+	js, err := nc.JetStream(
+		nats.PublishAsyncMaxPending(maxPendingAcks),
+		nats.PublishAsyncErrHandler(func(_ nats.JetStream, msg *nats.Msg, err error) {
+			broker.failPublish(errors.Wrapf(err, "failed to store a message published to %s", msg.Subject))
+		}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a JetStream context")
+	}
+	broker.js = js
+
+	if options.MaxAge > 0 {
+		for name := range js.StreamNames() {
+			if strings.HasPrefix(name, scopedStreamPrefix) {
+				broker.deleteIdleStream(strings.TrimPrefix(name, scopedStreamPrefix))
+			}
+		}
+	}
+	return broker, nil
+}
+
+func scopedSubject(subject string, topic cqrs.RouteKey) string {
+	return cqrs.RouteKey(scopedSubjectPrefix).With(cqrs.RouteKey(subject), topic).String()
+}
+
+func scopedStreamName(subject string) string {
+	return scopedStreamPrefix + subject
+}
+
+// validSubjectToken returns true if the subject can be used as a single token of a NATS subject and in a stream name
+func validSubjectToken(subject string) bool {
+	return subject != "" && !strings.ContainsAny(subject, ".*> \t\r\n/\\")
+}
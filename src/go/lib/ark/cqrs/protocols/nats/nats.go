@@ -39,18 +39,41 @@ func (b Broker) Subscribe(
 	topic cqrs.RouteKey,
 	_ *time.Duration,
 ) (<-chan cqrs.Envelope, error) {
+	return b.subscribe(ctx, topic.String())
+}
+
+// subscribe registers interest in every subject and delivers their messages to a single channel
+func (b Broker) subscribe(ctx context.Context, subjects ...string) (<-chan cqrs.Envelope, error) {
 	stream := make(chan cqrs.Envelope)
-	sub, err := b.nc.Subscribe(topic.String(), func(msg *nats.Msg) {
-		stream <- cqrs.NewEnvelope(cqrs.FromData(msg.Data))
-	})
-	if err != nil {
-		close(stream)
-		return stream, err
+	handler := func(msg *nats.Msg) {
+		// a subscriber that stopped reading must not block the drain of its subscription
+		select {
+		case stream <- cqrs.NewEnvelope(cqrs.FromData(msg.Data)):
+		case <-ctx.Done():
+		}
+	}
+
+	subs := make([]*nats.Subscription, 0, len(subjects))
+	for _, subject := range subjects {
+		sub, err := b.nc.Subscribe(subject, handler)
+		if err != nil {
+			for _, s := range subs {
+				_ = s.Unsubscribe()
+			}
+			// the stream is only closed when no handler could be sending to it
+			if len(subs) == 0 {
+				close(stream)
+			}
+			return stream, err
+		}
+		subs = append(subs, sub)
 	}
 
 	go func() {
 		<-ctx.Done()
-		_ = sub.Drain()
+		for _, sub := range subs {
+			_ = sub.Drain()
+		}
 	}()
 
 	return stream, nil
@@ -85,12 +108,20 @@ var DefaultServerOptions = server.Options{
 	// FB Watchman initial file scan is HUGE
 	// MaxPayload:            2e+6,
 
-	// FIXME: enable for message guarantees
-	// JetStream: false,
+	// JetStream is enabled by JetStreamServerOptions for the JetStreamBroker
 
 	DisableShortFirstPing: true,
 }
 
+// JetStreamServerOptions returns the default server options with JetStream enabled
+// Streams are persisted to storeDir so they survive a restart of the server
+func JetStreamServerOptions(storeDir string) server.Options {
+	opts := DefaultServerOptions
+	opts.JetStream = true
+	opts.StoreDir = storeDir
+	return opts
+}
+
 func RunServer(opts *server.Options) (*server.Server, error) {
 	if opts == nil {
 		opts = &DefaultServerOptions
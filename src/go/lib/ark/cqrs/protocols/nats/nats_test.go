@@ -2,8 +2,10 @@ package nats
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/myfintech/ark/src/go/lib/utils"
 
@@ -57,3 +59,152 @@ func TestBroker(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, data)
 }
+
+func TestJetStreamBroker(t *testing.T) {
+	runnerEvents := cqrs.RouteKey("graph.runner.events")
+	walkerEvents := cqrs.RouteKey("graph.walker.events")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opts := JetStreamServerOptions(t.TempDir())
+	opts.HTTPPort = 0
+	freePort, err := utils.GetFreePort()
+	require.NoError(t, err)
+	opts.Port, err = strconv.Atoi(freePort)
+	require.NoError(t, err)
+	instance, err := RunServer(&opts)
+	require.NoError(t, err)
+	defer instance.Shutdown()
+
+	nc, err := nats.Connect(instance.ClientURL())
+	require.NoError(t, err)
+
+	broker, err := NewJetStreamBroker(nc, JetStreamOptions{
+		Topics:  []cqrs.RouteKey{runnerEvents, walkerEvents},
+		Storage: nats.MemoryStorage,
+	})
+	require.NoError(t, err)
+	require.Implements(t, (*cqrs.Broker)(nil), broker)
+	require.Implements(t, (*cqrs.Replayer)(nil), broker)
+
+	publish := func(topic cqrs.RouteKey, subject, eventType string) {
+		message := cqrs.NewDefaultEnvelope(
+			cqrs.WithSource("example/uri"),
+			cqrs.WithType(cqrs.RouteKey(eventType)),
+			cqrs.WithSubject(cqrs.RouteKey(subject)),
+			cqrs.WithData(cloudevents.ApplicationJSON, map[string]string{
+				"hello": "world",
+			}),
+		)
+		require.NoError(t, message.Error)
+		require.NoError(t, broker.Publish(topic, &message))
+	}
+
+	t.Run("should deliver retained messages to subscribers of the topic", func(t *testing.T) {
+		stream, err := broker.Subscribe(ctx, walkerEvents, nil)
+		require.NoError(t, err)
+
+		publish(runnerEvents, "run-1", "started")
+		publish(walkerEvents, "run-1", "action.started")
+
+		received := <-stream
+		require.NoError(t, received.Error)
+		require.Equal(t, "action.started", received.Type())
+		require.Equal(t, "run-1", received.Subject())
+	})
+
+	t.Run("should replay the messages of a subject from the start", func(t *testing.T) {
+		publish(walkerEvents, "run-2", "action.started")
+		publish(walkerEvents, "", "action.started")
+		publish(runnerEvents, "run-1", "success")
+
+		stream, err := broker.Replay(ctx, "graph.*.events", "run-1")
+		require.NoError(t, err)
+
+		for _, expected := range []string{"started", "action.started", "success"} {
+			received := <-stream
+			require.NoError(t, received.Error)
+			require.Equal(t, "run-1", received.Subject())
+			require.Equal(t, expected, received.Type())
+		}
+	})
+
+	t.Run("should follow a subject without a stream without creating one", func(t *testing.T) {
+		stream, err := broker.Replay(ctx, walkerEvents, "run-3")
+		require.NoError(t, err)
+
+		_, err = broker.js.StreamInfo(scopedStreamName("run-3"))
+		require.True(t, errors.Is(err, nats.ErrStreamNotFound))
+
+		publish(walkerEvents, "run-3", "action.started")
+		received := <-stream
+		require.NoError(t, received.Error)
+		require.Equal(t, "run-3", received.Subject())
+	})
+
+	t.Run("should delete the stream of a subject after it ended", func(t *testing.T) {
+		expiring, err := NewJetStreamBroker(nc, JetStreamOptions{
+			Topics:     []cqrs.RouteKey{runnerEvents},
+			Storage:    nats.MemoryStorage,
+			MaxAge:     time.Second,
+			FinalTypes: []cqrs.RouteKey{"success"},
+		})
+		require.NoError(t, err)
+
+		message := cqrs.NewDefaultEnvelope(
+			cqrs.WithSource("example/uri"),
+			cqrs.WithType("success"),
+			cqrs.WithSubject("run-4"),
+			cqrs.WithData(cloudevents.ApplicationJSON, map[string]string{}),
+		)
+		require.NoError(t, expiring.Publish(runnerEvents, &message))
+
+		_, err = expiring.js.StreamInfo(scopedStreamName("run-4"))
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			_, infoErr := expiring.js.StreamInfo(scopedStreamName("run-4"))
+			return errors.Is(infoErr, nats.ErrStreamNotFound)
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("should close the replay after the final message of a subject", func(t *testing.T) {
+		ending, err := NewJetStreamBroker(nc, JetStreamOptions{
+			Topics:     []cqrs.RouteKey{runnerEvents},
+			Storage:    nats.MemoryStorage,
+			FinalTypes: []cqrs.RouteKey{"success"},
+		})
+		require.NoError(t, err)
+
+		for _, eventType := range []string{"started", "success"} {
+			message := cqrs.NewDefaultEnvelope(
+				cqrs.WithSource("example/uri"),
+				cqrs.WithType(cqrs.RouteKey(eventType)),
+				cqrs.WithSubject("run-5"),
+				cqrs.WithData(cloudevents.ApplicationJSON, map[string]string{}),
+			)
+			require.NoError(t, ending.Publish(runnerEvents, &message))
+		}
+
+		stream, err := ending.Replay(ctx, runnerEvents, "run-5")
+		require.NoError(t, err)
+
+		for _, expected := range []string{"started", "success"} {
+			received := <-stream
+			require.NoError(t, received.Error)
+			require.Equal(t, expected, received.Type())
+		}
+
+		select {
+		case _, ok := <-stream:
+			require.False(t, ok)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the replay was not closed after the final message")
+		}
+	})
+
+	t.Run("should reject subjects that can't scope a stream", func(t *testing.T) {
+		_, err := broker.Replay(ctx, walkerEvents, "run.1")
+		require.Error(t, err)
+	})
+}
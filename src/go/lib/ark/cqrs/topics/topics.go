@@ -18,6 +18,10 @@ var (
 	// GraphWalkerEvents the GraphWalker events topic
 	GraphWalkerEvents = GraphWalker.With("events")
 
+	// GraphEvents a wildcard topic matching the GraphRunner and GraphWalker events topics
+	// A single subscription receives the events of a run in the order they were published
+	GraphEvents = cqrs.RouteKey("graph.*.events")
+
 	// HTTPServer a system level topic
 	HTTPServer = cqrs.RouteKey("http.server")
 
@@ -51,6 +55,11 @@ var (
 	FSObserverEvents = FSObserver.With("events")
 )
 
+var (
+	// RunRecorder a system level topic
+	RunRecorder = cqrs.RouteKey("run.recorder")
+)
+
 var (
 	// K8sEcho a system level topic
 	K8sEcho = cqrs.RouteKey("k8s.echo")
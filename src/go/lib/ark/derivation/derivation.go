@@ -6,7 +6,11 @@ import (
 	"github.com/myfintech/ark/src/go/lib/ark/targets/deploy"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/docker_image"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/group"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/http_archive"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/jsonnet"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/kube_exec"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/kube_secret"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/local_exec"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/local_file"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/nix"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/probe"
@@ -34,8 +38,16 @@ func TargetFromRawTarget(rawTarget ark.RawTarget) (target ark.Target, err error)
 		target = &test.Target{RawTarget: rawTarget}
 	case local_file.Type:
 		target = &local_file.Target{RawTarget: rawTarget}
+	case local_exec.Type:
+		target = &local_exec.Target{RawTarget: rawTarget}
 	case nix.Type:
 		target = &nix.Target{RawTarget: rawTarget}
+	case http_archive.Type:
+		target = &http_archive.Target{RawTarget: rawTarget}
+	case jsonnet.Type:
+		target = &jsonnet.Target{RawTarget: rawTarget}
+	case kube_secret.Type:
+		target = &kube_secret.Target{RawTarget: rawTarget}
 	default:
 		return nil, errors.Errorf("invalid target type %s", rawTarget.Type)
 	}
@@ -48,7 +60,7 @@ func TargetFromRawTarget(rawTarget ark.RawTarget) (target ark.Target, err error)
 
 // TargetAndArtifactFromRawTarget derives an ark.Artifact from an ark.RawTarget by type
 func TargetAndArtifactFromRawTarget(rawTarget ark.RawTarget) (target ark.Target, artifact ark.Artifact, err error) {
-	checksum, err := rawTarget.Checksum()
+	checksum, inputs, err := rawTarget.ChecksumInputs()
 	if err != nil {
 		return
 	}
@@ -63,6 +75,9 @@ func TargetAndArtifactFromRawTarget(rawTarget ark.RawTarget) (target ark.Target,
 		return
 	}
 
+	if recorder, ok := artifact.(ark.HashInputsRecorder); ok {
+		recorder.RecordHashInputs(inputs)
+	}
 	return
 }
 
@@ -106,8 +121,16 @@ func ActionFromTargetAndArtifact(target ark.Target, artifact ark.Artifact) (acti
 		action = &test.Action{Target: t, Artifact: artifact.(*test.Artifact)}
 	case *local_file.Target:
 		action = &local_file.Action{Target: t, Artifact: artifact.(*local_file.Artifact)}
+	case *local_exec.Target:
+		action = &local_exec.Action{Target: t, Artifact: artifact.(*local_exec.Artifact)}
 	case *nix.Target:
 		action = nix.Action{Target: t, Artifact: artifact.(*nix.Artifact)}
+	case *http_archive.Target:
+		action = &http_archive.Action{Target: t, Artifact: artifact.(*http_archive.Artifact)}
+	case *jsonnet.Target:
+		action = &jsonnet.Action{Target: t, Artifact: artifact.(*jsonnet.Artifact)}
+	case *kube_secret.Target:
+		action = &kube_secret.Action{Target: t, Artifact: artifact.(*kube_secret.Artifact)}
 	}
 	return
 }
@@ -3,7 +3,11 @@ import {
     DeployTarget,
     DockerImageTarget,
     GroupTarget,
+    HttpArchiveTarget,
+    JsonnetTarget,
     KubeExecTarget,
+    KubeSecretTarget,
+    LocalExecTarget,
     LocalFileTarget,
     NixTarget,
     ProbeTarget,
@@ -14,7 +18,11 @@ import {
     DeployArtifact,
     DockerImageArtifact,
     GroupArtifact,
+    HttpArchiveArtifact,
+    JsonnetArtifact,
     KubeExecArtifact,
+    KubeSecretArtifact,
+    LocalExecArtifact,
     LocalFileArtifact,
     NixArtifact,
     ProbeArtifact,
@@ -38,8 +46,16 @@ export type DeployAction = Action<DeployTarget, DeployArtifact>
 //not using generic because we exclude 'attributes'
 export type GroupAction = (target: GroupTarget) => GroupTarget
 
+export type HttpArchiveAction = Action<HttpArchiveTarget, HttpArchiveArtifact>
+
+export type JsonnetAction = Action<JsonnetTarget, JsonnetArtifact>
+
 export type KubeExecAction = Action<KubeExecTarget, KubeExecArtifact>
 
+export type KubeSecretAction = Action<KubeSecretTarget, KubeSecretArtifact>
+
+export type LocalExecAction = Action<LocalExecTarget, LocalExecArtifact>
+
 export type LocalFileAction = Action<LocalFileTarget, LocalFileArtifact>
 
 export type NixAction = Action<NixTarget, NixArtifact>
@@ -75,6 +91,22 @@ export const deploy: DeployAction
  */
 export const group: GroupAction
 
+/**
+ * httpArchive is an action that downloads a file and verifies it against its sha256 checksum
+ * archives are optionally extracted and the result is cached by checksum so it's never downloaded twice
+ * @param {HttpArchiveTarget} target -
+ * @returns {HttpArchiveArtifact} -
+ */
+export const httpArchive: HttpArchiveAction
+
+/**
+ * jsonnet is an action that renders jsonnet files with library dirs and external variables into a cached artifact
+ * the rendered files can be passed to a deploy target as manifestFiles
+ * @param {JsonnetTarget} target -
+ * @returns {JsonnetArtifact} -
+ */
+export const jsonnet: JsonnetAction
+
 /**
  * kubeExec is an action that execute a command in already running pod in kubernetes
  * @param {KubeExecTarget} target -
@@ -82,6 +114,22 @@ export const group: GroupAction
  */
 export const kubeExec: KubeExecAction
 
+/**
+ * kubeSecret is an action that decrypts paths from the KV store and renders a k8s Secret manifest
+ * the rendered manifest can be passed to a deploy target as manifestFiles
+ * @param {KubeSecretTarget} target -
+ * @returns {KubeSecretArtifact} -
+ */
+export const kubeSecret: KubeSecretAction
+
+/**
+ * localExec is an action that executes a command on the host as a cached graph node
+ * the declared outputs of the target are captured into the artifact and restored on a cache hit
+ * @param {LocalExecTarget} target -
+ * @returns {LocalExecArtifact} -
+ */
+export const localExec: LocalExecAction
+
 /**
  * localFile is an action that execute
  * @param {LocalFileTarget} target -
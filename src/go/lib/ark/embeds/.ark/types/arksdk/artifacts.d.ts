@@ -14,6 +14,29 @@ export type NixArtifactAttributes = Attributes & {
     packages: string[]
 }
 
+/**
+ * HttpArchiveArtifactAttributes contain the location of the verified download or its extracted contents
+ */
+export type HttpArchiveArtifactAttributes = Attributes & {
+    contentsPath: string
+}
+
+/**
+ * JsonnetArtifactAttributes contain the locations of the rendered files in the order the files were declared
+ */
+export type JsonnetArtifactAttributes = Attributes & {
+    renderedFiles: string[]
+}
+
+/**
+ * KubeSecretArtifactAttributes contain the location of the rendered Secret manifest
+ * the manifest is written outside of the artifact cache because it contains plaintext
+ * and it is removed once the run that rendered it finished
+ */
+export type KubeSecretArtifactAttributes = Attributes & {
+    renderedFilePath: string
+}
+
 /**
  * LocalFileArtifactAttributes contain the contents of the rendered local file
  */
@@ -28,8 +51,16 @@ export type DockerImageArtifact = RawArtifact<DockerImageArtifactAttributes>
 
 export type GroupArtifact = RawArtifact
 
+export type HttpArchiveArtifact = RawArtifact<HttpArchiveArtifactAttributes>
+
+export type JsonnetArtifact = RawArtifact<JsonnetArtifactAttributes>
+
 export type KubeExecArtifact = RawArtifact
 
+export type KubeSecretArtifact = RawArtifact<KubeSecretArtifactAttributes>
+
+export type LocalExecArtifact = RawArtifact
+
 export type LocalFileArtifact = RawArtifact<LocalFileArtifactAttributes>
 
 export type NixArtifact = RawArtifact<NixArtifactAttributes>
@@ -50,6 +50,38 @@ export type RawTarget<T extends Attributes = Attributes> = {
   dependsOn?: Ancestor[];
   excludeFromHash?: ExcludeFromHash;
   ignoreFileNotExistsError?: boolean;
+  /**
+   * files or directories relative to the build file that the action produces (local_exec targets declare them relative to their dir)
+   * they are archived into the artifact and restored into the workspace on a cache hit
+   */
+  outputs?: string[];
+  /**
+   * controls how the graph walker executes the action of the target
+   */
+  policy?: ExecutionPolicy;
+  /**
+   * the weight the action consumes from each resource pool while it executes
+   * the capacity of each pool is configured in the scheduler.pools section of .ark/settings.json
+   * @example
+   *  { docker: 2, cpu: 4 }
+   */
+  resources?: Record<string, number>;
+};
+
+/**
+ * An execution policy that applies to any target
+ * @example
+ *  { timeoutSeconds: 300, maxAttempts: 3, backoffSeconds: 5 }
+ */
+export type ExecutionPolicy = {
+  /** the maximum duration of a single attempt, 0 disables the timeout */
+  timeoutSeconds?: number;
+  /** the number of times the action is executed before it is reported as failed, defaults to 1 */
+  maxAttempts?: number;
+  /** the delay before the first retry, the delay doubles after every failed attempt */
+  backoffSeconds?: number;
+  /** reports a failed action without failing the graph */
+  allowFailure?: boolean;
 };
 
 /**
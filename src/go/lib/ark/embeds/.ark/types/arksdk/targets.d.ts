@@ -21,7 +21,8 @@ export type DeployTargetAttributes = Attributes & {
   liveSyncEnabled?: boolean;
   liveSyncRestartMode?: string;
   liveSyncOnStep?: Step[];
-  manifest: Manifest;
+  manifest?: Manifest;
+  manifestFiles?: string[];
   portForward?: PortMap;
 };
 
@@ -38,6 +39,30 @@ export type DockerImageTargetAttributes = Attributes & {
   secrets?: string[];
 };
 
+/**
+ * Represents the target state of a file downloaded over http and pinned by its sha256 checksum
+ * archives can optionally be extracted, stripPrefix requires decompress
+ */
+export type HttpArchiveTargetAttributes = Attributes & {
+  url: string;
+  sha256: string;
+  decompress?: boolean;
+  format?: "tar.gz" | "zip";
+  stripPrefix?: string;
+};
+
+/**
+ * Represents the target state of rendering jsonnet files
+ * the contents of the files and of every file in the library directories are part of the artifact hash
+ */
+export type JsonnetTargetAttributes = Attributes & {
+  files: string[];
+  libraryDirs?: string[];
+  extVars?: Record<string, string>;
+  extCode?: Record<string, string>;
+  format?: "json" | "yaml";
+};
+
 /**
  * Represents the target state of executing a command in a k8s container
  */
@@ -49,6 +74,35 @@ export type KubeExecTargetAttributes = Attributes & {
   timeoutSeconds: number;
 };
 
+/**
+ * Represents the target state of a command executed on the host
+ * the files the command produces should be declared as outputs of the target relative to dir
+ */
+export type LocalExecTargetAttributes = Attributes & {
+  command: string;
+  args?: string[];
+  environment?: Record<string, string>;
+  dir?: string;
+  timeoutSeconds?: number;
+  /**
+   * runs the command in a temporary execroot that only contains the source files and the outputs of the dependencies
+   * the environment only contains the declared variables, PATH defaults to /usr/local/bin:/usr/bin:/bin:/usr/sbin:/sbin
+   * reading an undeclared file of the workspace fails, this requires bwrap or unshare on Linux and sandbox-exec on macOS
+   */
+  sandbox?: boolean;
+};
+
+/**
+ * Represents the target state of a k8s Secret rendered from paths in the encrypted KV store
+ */
+export type KubeSecretTargetAttributes = Attributes & {
+  secretName: string;
+  namespace?: string;
+  secretType?: string;
+  paths: string[];
+  secretLabels?: Record<string, string>;
+};
+
 export type LocalFileTargetAttributes = Attributes & {
   filename: string;
   content: string;
@@ -102,8 +156,16 @@ export type DockerImageTarget = RawTarget<DockerImageTargetAttributes>;
 
 export type GroupTarget = Omit<RawTarget, "attributes">;
 
+export type HttpArchiveTarget = RawTarget<HttpArchiveTargetAttributes>;
+
+export type JsonnetTarget = RawTarget<JsonnetTargetAttributes>;
+
 export type KubeExecTarget = RawTarget<KubeExecTargetAttributes>;
 
+export type KubeSecretTarget = RawTarget<KubeSecretTargetAttributes>;
+
+export type LocalExecTarget = RawTarget<LocalExecTargetAttributes>;
+
 export type LocalFileTarget = RawTarget<LocalFileTargetAttributes>;
 
 export type NixTarget = RawTarget<NixTargetAttributes>;
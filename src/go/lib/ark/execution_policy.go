@@ -0,0 +1,77 @@
+package ark
+
+import (
+	"database/sql/driver"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/myfintech/ark/src/go/lib/gorm/json_datatypes"
+)
+
+// ExecutionPolicy controls how the graph walker executes the action of any target
+type ExecutionPolicy struct {
+	// TimeoutSeconds the maximum duration of a single attempt, 0 disables the timeout
+	TimeoutSeconds int `json:"timeoutSeconds" mapstructure:"timeoutSeconds"`
+
+	// MaxAttempts the number of times the action is executed before it is reported as failed, defaults to 1
+	MaxAttempts int `json:"maxAttempts" mapstructure:"maxAttempts"`
+
+	// BackoffSeconds the delay before the first retry, the delay doubles after every failed attempt
+	BackoffSeconds int `json:"backoffSeconds" mapstructure:"backoffSeconds"`
+
+	// AllowFailure reports a failed action without failing the graph, dependents of the target are still executed
+	AllowFailure bool `json:"allowFailure" mapstructure:"allowFailure"`
+}
+
+// Attempts returns the number of times the action should be executed
+func (p ExecutionPolicy) Attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// Timeout returns the maximum duration of a single attempt or zero if there is no timeout
+func (p ExecutionPolicy) Timeout() time.Duration {
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
+// Backoff returns the delay before the attempt following the given failed attempt
+func (p ExecutionPolicy) Backoff(failedAttempt int) time.Duration {
+	if p.BackoffSeconds <= 0 || failedAttempt < 1 {
+		return 0
+	}
+	return time.Duration(p.BackoffSeconds) * time.Second << (failedAttempt - 1)
+}
+
+// Validate checks if the policy fields are valid
+func (p ExecutionPolicy) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.TimeoutSeconds, validation.Min(0)),
+		validation.Field(&p.MaxAttempts, validation.Min(0)),
+		validation.Field(&p.BackoffSeconds, validation.Min(0)),
+	)
+}
+
+// Value return json value, implement driver.Valuer interface
+func (p ExecutionPolicy) Value() (driver.Value, error) {
+	return json_datatypes.MarshalString(&p)
+}
+
+// Scan scan value into Jsonb, implements sql.Scanner interface
+func (p *ExecutionPolicy) Scan(val interface{}) error {
+	return json_datatypes.Scan(val, p)
+}
+
+// GormDataType gorm common data type
+func (p ExecutionPolicy) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType gorm db data type
+func (ExecutionPolicy) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	return json_datatypes.DetermineDBDataType(db)
+}
@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/dag"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+// errWalkAborted is returned for targets that are not executed because an earlier target failed
+var errWalkAborted = errors.New("the graph walk was aborted because a target failed")
+
+// TargetFailure the key of a target that failed and its error
+type TargetFailure struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// FailureReport an error listing every target that failed and every target that was blocked
+// A target is blocked when it depends on a failed target or when it was never started because the walk was aborted
+type FailureReport struct {
+	Failed  []TargetFailure `json:"failed"`
+	Blocked []string        `json:"blocked"`
+}
+
+// Error implements the error interface
+func (r *FailureReport) Error() string {
+	lines := []string{
+		fmt.Sprintf("%d target(s) failed and %d target(s) were blocked", len(r.Failed), len(r.Blocked)),
+	}
+	for _, failure := range r.Failed {
+		lines = append(lines, fmt.Sprintf("failed %s: %s", failure.Key, failure.Error))
+	}
+	for _, key := range r.Blocked {
+		lines = append(lines, fmt.Sprintf("blocked %s", key))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// walkResults records the outcome of every target visited by the execution walk
+type walkResults struct {
+	keepGoing bool
+	mu        sync.Mutex
+	visited   map[string]bool
+	failed    map[string]string
+	disposers []ark.Disposer
+}
+
+func newWalkResults(keepGoing bool) *walkResults {
+	return &walkResults{
+		keepGoing: keepGoing,
+		visited:   make(map[string]bool),
+		failed:    make(map[string]string),
+	}
+}
+
+// aborted returns true if a target failed and the walk should not start any other target
+func (r *walkResults) aborted() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.keepGoing && len(r.failed) > 0
+}
+
+func (r *walkResults) visit(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.visited[key] = true
+}
+
+func (r *walkResults) fail(key string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed[key] = err.Error()
+}
+
+// retain retains an artifact that is disposed of once the walk finished
+func (r *walkResults) retain(disposer ark.Disposer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	disposer.Retain()
+	r.disposers = append(r.disposers, disposer)
+}
+
+// dispose disposes of every artifact retained during the walk
+func (r *walkResults) dispose(logger logz.FieldLogger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, disposer := range r.disposers {
+		if err := disposer.Dispose(); err != nil {
+			logger.Warnf("failed to dispose of an artifact %v", err)
+		}
+	}
+	r.disposers = nil
+}
+
+// report returns the failures of the walk and the targets of the graph that were never visited
+// It returns nil if no target failed
+func (r *walkResults) report(graph *dag.AcyclicGraph) (*FailureReport, []ark.RawTarget, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.failed) == 0 {
+		return nil, nil, nil
+	}
+
+	report := new(FailureReport)
+	for key, err := range r.failed {
+		report.Failed = append(report.Failed, TargetFailure{Key: key, Error: err})
+	}
+	sort.Slice(report.Failed, func(i, j int) bool {
+		return report.Failed[i].Key < report.Failed[j].Key
+	})
+
+	var blocked []ark.RawTarget
+	for _, vertex := range graph.Vertices() {
+		target, err := rawTargetFromVertex(vertex)
+		if err != nil {
+			return nil, nil, err
+		}
+		if r.visited[target.Key()] {
+			continue
+		}
+		blocked = append(blocked, target)
+	}
+	sort.Slice(blocked, func(i, j int) bool {
+		return blocked[i].Key() < blocked[j].Key()
+	})
+
+	for _, target := range blocked {
+		report.Blocked = append(report.Blocked, target.Key())
+	}
+	return report, blocked, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/myfintech/ark/src/go/lib/container"
 	"github.com/myfintech/ark/src/go/lib/kube"
@@ -36,38 +37,60 @@ type ExecuteOptions struct {
 	Ctx                         context.Context
 	Store                       ark.Store
 	SharedClients               *shared_clients.Container
-	RootTargetKey               string
+	RootTargetKeys              []string
+	Labels                      ark.LabelSelector
 	PushArtifactsAfterExecution bool
 	SubscriptionID              string
 	Broker                      cqrs.Broker
 	SkipFilters                 []string
+	SkipMode                    SkipMode
 	K8sNamespace                string
 	K8sContext                  string
 	ForceExecution              bool
+	KeepGoing                   bool
 	Logger                      logz.FieldLogger
 	MaxConcurrency              int
+
+	// ResourcePools the capacity of each resource pool, targets declare the weight they consume with ark.RawTarget.Resources
+	ResourcePools map[string]int64
+
+	// SharedExecutions coalesces the execution of artifacts that are built by concurrent runs
+	SharedExecutions *SharedExecutions
 }
 
 var topic = topics.GraphWalkerEvents
 
 // Execute executes a parallel walk of the graph derived from the supplied ark.Store
+// The sub graphs of every root target are merged so shared dependencies are only walked once
 // If ExecuteOptions.MaxConcurrency is 0 it will be set to runtime.GOMAXPROCS(0)
+// By default no target is started after the first failure, with ExecuteOptions.KeepGoing every target whose dependencies succeeded is executed
+// If any target fails a *FailureReport listing every failed and blocked target is returned
 func Execute(opts ExecuteOptions) error {
 	if opts.MaxConcurrency == 0 {
 		opts.MaxConcurrency = runtime.GOMAXPROCS(0)
 	}
 
+	if len(opts.RootTargetKeys) == 0 && opts.Labels.Empty() {
+		return errors.New("at least one root target key or label is required")
+	}
+
 	graph, err := opts.Store.GetGraph()
 	if err != nil {
 		return err
 	}
 
-	rootVertex, err := opts.Store.GetTargetByKey(opts.RootTargetKey)
+	rootVertices, err := selectRootVertices(opts)
+	if err != nil {
+		return err
+	}
+
+	graph = graph.IsolateAll(rootVertices...)
+
+	graph, skipped, err := pruneSkipped(graph, rootVertices, opts.SkipFilters, opts.SkipMode)
 	if err != nil {
 		return err
 	}
 
-	graph = graph.Isolate(rootVertex)
 	if err = graph.WalkWithErr(validationWalk(opts)); err != nil {
 		return err
 	}
@@ -81,7 +104,61 @@ func Execute(opts ExecuteOptions) error {
 		return err
 	}
 
-	return graph.WalkWithErr(newExecutionWalkFunc(opts))
+	results := newWalkResults(opts.KeepGoing)
+	defer results.dispose(opts.Logger)
+	walkErr := graph.WalkWithErr(newExecutionWalkFunc(opts, graph, skipped, results))
+
+	report, blocked, err := results.report(graph)
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		return walkErr
+	}
+
+	for _, target := range blocked {
+		if err = opts.Broker.Publish(topic, cqrs.NewDefaultEnvelope(
+			sources.GraphWalkerSource,
+			events.GraphWalkerActionBlockedType,
+			cqrs.WithSubject(cqrs.RouteKey(opts.SubscriptionID)),
+			cqrs.WithData(cqrs.ApplicationJSON, ark.Derivative{RawTarget: target}),
+		)); err != nil {
+			return err
+		}
+	}
+	return report
+}
+
+// selectRootVertices loads the root targets and filters them by label
+// If no root target keys are given every target in the store is a candidate
+// Labels only select roots, the dependencies of a selected root are always walked
+func selectRootVertices(opts ExecuteOptions) ([]dag.Vertex, error) {
+	var candidates []ark.RawTarget
+	if len(opts.RootTargetKeys) == 0 {
+		targets, err := opts.Store.GetTargets()
+		if err != nil {
+			return nil, err
+		}
+		candidates = targets
+	}
+
+	for _, key := range opts.RootTargetKeys {
+		target, err := opts.Store.GetTargetByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, target)
+	}
+
+	var rootVertices []dag.Vertex
+	for _, target := range opts.Labels.Filter(candidates) {
+		rootVertices = append(rootVertices, target)
+	}
+
+	if len(rootVertices) == 0 {
+		return nil, errors.Errorf("no root targets matched the labels %v excluding %v", opts.Labels.Include, opts.Labels.Exclude)
+	}
+	return rootVertices, nil
 }
 
 func validationWalk(opts ExecuteOptions) func(vertex dag.Vertex) (err error) {
@@ -109,17 +186,11 @@ func validationWalk(opts ExecuteOptions) func(vertex dag.Vertex) (err error) {
 	}
 }
 
-func newExecutionWalkFunc(opts ExecuteOptions) dag.WalkFuncWithErr {
+func newExecutionWalkFunc(opts ExecuteOptions, graph *dag.AcyclicGraph, skipped map[string]bool, results *walkResults) dag.WalkFuncWithErr {
 	sem := semaphore.NewWeighted(int64(opts.MaxConcurrency))
+	pools := newResourcePools(opts.ResourcePools)
 	subject := cqrs.WithSubject(cqrs.RouteKey(opts.SubscriptionID))
 	return func(vertex dag.Vertex) (err error) {
-		err = sem.Acquire(opts.Ctx, 1)
-		if err != nil {
-			err = errors.Wrap(err, "failed to acquire semaphore")
-			return
-		}
-		defer sem.Release(1)
-
 		var rawTarget ark.RawTarget
 
 		// the graph isolation function produces a graph of pointers
@@ -133,17 +204,47 @@ func newExecutionWalkFunc(opts ExecuteOptions) dag.WalkFuncWithErr {
 			err = errors.Errorf("graph walk cannot continue %T is not type ark.RawTarget", vertex)
 			return
 		}
+
+		// resource pools are acquired before the concurrency semaphore
+		// so targets waiting on a busy pool don't hold slots that cheaper targets could use
+		release, err := pools.acquire(opts.Ctx, rawTarget.Resources)
+		if err != nil {
+			return
+		}
+		defer release()
+
+		err = sem.Acquire(opts.Ctx, 1)
+		if err != nil {
+			err = errors.Wrap(err, "failed to acquire semaphore")
+			return
+		}
+		defer sem.Release(1)
+		startedAt := time.Now()
+
+		if results.aborted() {
+			return errWalkAborted
+		}
+		results.visit(rawTarget.Key())
+		defer func() {
+			if err != nil {
+				results.fail(rawTarget.Key(), err)
+			}
+		}()
+
 		target, artifact, err := derivation.TargetAndArtifactFromRawTarget(rawTarget)
 		if err != nil {
 			return
 		}
 
 		derivative := ark.Derivation{
-			Target:   target,
-			Artifact: artifact,
+			Target:    target,
+			Artifact:  artifact,
+			StartedAt: startedAt,
 		}
 		defer func() {
 			if err != nil {
+				derivative.Error = err.Error()
+				derivative.Duration = time.Since(startedAt)
 				_ = opts.Broker.Publish(topic, cqrs.NewDefaultEnvelope(
 					subject,
 					sources.GraphWalkerSource,
@@ -165,12 +266,60 @@ func newExecutionWalkFunc(opts ExecuteOptions) dag.WalkFuncWithErr {
 		// injects artifacts with shared clients before verification
 		opts.SharedClients.Inject(artifact)
 
-		cached, err := verifyArtifact(opts.Ctx, artifact)
+		if skipped[target.Key()] {
+			if opts.SkipMode == SkipModeLastKnown && artifact.Cacheable() {
+				if err = useLastKnownArtifact(opts, graph, vertex, skipped, target, rawTarget, &derivative); err != nil {
+					return err
+				}
+			}
+
+			derivative.Duration = time.Since(startedAt)
+			return opts.Broker.Publish(topic, cqrs.NewDefaultEnvelope(
+				subject,
+				sources.GraphWalkerSource,
+				events.GraphWalkerActionSkippedType,
+				cqrs.WithData(cqrs.ApplicationJSON, derivative),
+			))
+		}
+
+		rawArtifact, err := derivation.RawArtifactFromArtifact(artifact)
 		if err != nil {
 			return
 		}
 
+		// a concurrent run building the same artifact is awaited so its result is verified as cached below
+		if artifact.Cacheable() {
+			var release func()
+			release, err = opts.SharedExecutions.acquire(opts.Ctx, rawArtifact.Hash, opts.SubscriptionID, func(owner string) error {
+				opts.Logger.Infof("%s is awaiting the execution of %s shared with run %s", target.Key(), rawArtifact.ShortHash(), owner)
+				return opts.Broker.Publish(topic, cqrs.NewDefaultEnvelope(
+					subject,
+					sources.GraphWalkerSource,
+					events.GraphWalkerActionAwaitingSharedType,
+					cqrs.WithData(cqrs.ApplicationJSON, derivative),
+				))
+			})
+			if err != nil {
+				return
+			}
+			defer release()
+		}
+
+		cached, err := verifyArtifact(opts.Ctx, artifact, opts.Logger)
+		if err != nil {
+			return
+		}
+
+		// the declared outputs of a cached artifact are restored so the workspace looks as if the action ran
+		if cached && !opts.ForceExecution && len(rawTarget.Outputs) > 0 {
+			if restoreErr := artifact.RestoreOutputs(outputsRooter(target, rawTarget).OutputsRoot(), rawTarget.Outputs); restoreErr != nil {
+				opts.Logger.Warnf("executing %s because its outputs could not be restored %v", target.Key(), restoreErr)
+				cached = false
+			}
+		}
+
 		if cached && !opts.ForceExecution {
+			derivative.Duration = time.Since(startedAt)
 			if err = opts.Broker.Publish(topic, cqrs.NewDefaultEnvelope(
 				subject,
 				sources.GraphWalkerSource,
@@ -187,12 +336,15 @@ func newExecutionWalkFunc(opts ExecuteOptions) dag.WalkFuncWithErr {
 			return
 		}
 
-		rawArtifact, err := derivation.RawArtifactFromArtifact(artifact)
-		if err != nil {
-			return
+		opts.SharedClients.Inject(action)
+		if user, ok := action.(ark.DependencyOutputsUser); ok {
+			outputs, outputsErr := dependencyOutputs(graph, vertex)
+			if outputsErr != nil {
+				return outputsErr
+			}
+			user.UseDependencyOutputs(outputs)
 		}
 
-		opts.SharedClients.Inject(action)
 		input := injectOrSkipLoggerInput{
 			action:         action,
 			logger:         opts.Logger,
@@ -205,7 +357,19 @@ func newExecutionWalkFunc(opts ExecuteOptions) dag.WalkFuncWithErr {
 		}
 
 		// this function return a cleanup function and we defer its execution
-		defer injectOrSkipLogger(input)()
+		// an abandoned attempt may still write to its logger so the logger is only cleaned up once it returns
+		cleanupLogger := injectOrSkipLogger(input)
+		var running <-chan error
+		defer func() {
+			if running == nil {
+				cleanupLogger()
+				return
+			}
+			go func() {
+				<-running
+				cleanupLogger()
+			}()
+		}()
 
 		// inject actions with shared clients before execution
 		if err = opts.Broker.Publish(topic, cqrs.NewDefaultEnvelope(
@@ -217,10 +381,48 @@ func newExecutionWalkFunc(opts ExecuteOptions) dag.WalkFuncWithErr {
 			return err
 		}
 
-		if err = action.Execute(opts.Ctx); err != nil {
+		if disposer, ok := artifact.(ark.Disposer); ok {
+			results.retain(disposer)
+		}
+
+		attempts, err := executeWithPolicy(opts.Ctx, rawTarget.Policy, action, func(attempt int, attemptErr error) error {
+			opts.Logger.Warnf("retrying %s after attempt %d of %d failed %v", target.Key(), attempt, rawTarget.Policy.Attempts(), attemptErr)
+			retry := derivative
+			retry.Attempt = attempt
+			retry.Error = attemptErr.Error()
+			retry.Duration = time.Since(startedAt)
+			return opts.Broker.Publish(topic, cqrs.NewDefaultEnvelope(
+				subject,
+				sources.GraphWalkerSource,
+				events.GraphWalkerActionRetryType,
+				cqrs.WithData(cqrs.ApplicationJSON, retry),
+			))
+		})
+		derivative.Attempt = attempts
+		running = runningAttempt(err)
+		if err != nil && rawTarget.Policy.AllowFailure && opts.Ctx.Err() == nil {
+			// the artifact state is not written so the action is executed again on the next run
+			opts.Logger.Warnf("%s failed and its policy allows failure %v", target.Key(), err)
+			derivative.Error = err.Error()
+			derivative.Duration = time.Since(startedAt)
+			return opts.Broker.Publish(topic, cqrs.NewDefaultEnvelope(
+				subject,
+				sources.GraphWalkerSource,
+				events.GraphWalkerActionFailureAllowedType,
+				cqrs.WithData(cqrs.ApplicationJSON, derivative),
+			))
+		}
+		if err != nil {
 			return err
 		}
 
+		// outputs are captured before the state is written so an artifact is never cached without its outputs
+		if artifact.Cacheable() && len(rawTarget.Outputs) > 0 {
+			if err = artifact.CaptureOutputs(outputsRooter(target, rawTarget).OutputsRoot(), rawTarget.Outputs); err != nil {
+				return err
+			}
+		}
+
 		if err = artifact.WriteState(); err != nil {
 			return err
 		}
@@ -239,6 +441,7 @@ func newExecutionWalkFunc(opts ExecuteOptions) dag.WalkFuncWithErr {
 			}
 		}
 
+		derivative.Duration = time.Since(startedAt)
 		return opts.Broker.Publish(topic, cqrs.NewDefaultEnvelope(
 			subject,
 			sources.GraphWalkerSource,
@@ -323,7 +526,48 @@ func injectOrSkipLogger(input injectOrSkipLoggerInput) func() {
 
 }
 
-func verifyArtifact(ctx context.Context, artifact ark.Artifact) (bool, error) {
+// useLastKnownArtifact makes the last known artifact of a skipped target available to its dependents
+// the skipped target fails if it has dependents but was never built, so its dependents are blocked instead of running against a missing artifact
+func useLastKnownArtifact(
+	opts ExecuteOptions,
+	graph *dag.AcyclicGraph,
+	vertex dag.Vertex,
+	skipped map[string]bool,
+	target ark.Target,
+	rawTarget ark.RawTarget,
+	derivative *ark.Derivation,
+) error {
+	hasDependents, err := hasUnskippedDependents(graph, vertex, skipped)
+	if err != nil || !hasDependents {
+		return err
+	}
+
+	lastKnown, err := lastKnownArtifact(opts.Ctx, target.Key(), derivative.Artifact, opts.Logger)
+	if err != nil {
+		return err
+	}
+	if lastKnown == nil {
+		return errors.Errorf("%s was skipped but it was never built, its dependents can't run against its last known artifact", target.Key())
+	}
+
+	if lastKnown != derivative.Artifact {
+		rawArtifact, rawErr := derivation.RawArtifactFromArtifact(lastKnown)
+		if rawErr != nil {
+			return rawErr
+		}
+		opts.Logger.Infof("%s was skipped, its dependents run against its last known artifact %s", target.Key(), rawArtifact.ShortHash())
+		derivative.Artifact = lastKnown
+	}
+
+	if len(rawTarget.Outputs) > 0 {
+		if err = lastKnown.RestoreOutputs(outputsRooter(target, rawTarget).OutputsRoot(), rawTarget.Outputs); err != nil {
+			return errors.Wrapf(err, "%s was skipped and the outputs of its last known artifact could not be restored", target.Key())
+		}
+	}
+	return nil
+}
+
+func verifyArtifact(ctx context.Context, artifact ark.Artifact, logger logz.FieldLogger) (bool, error) {
 	if !artifact.Cacheable() {
 		return false, nil
 	}
@@ -342,9 +586,16 @@ func verifyArtifact(ctx context.Context, artifact ark.Artifact) (bool, error) {
 		return false, err
 	}
 
-	if remotelyCached {
-		return true, artifact.Pull(ctx)
+	if !remotelyCached {
+		return false, nil
+	}
+
+	// an artifact that cannot be pulled or fails verification is treated as a cache miss
+	// so a corrupted remote cache entry is rebuilt instead of breaking the build
+	if err = artifact.Pull(ctx); err != nil {
+		logger.Warnf("ignoring remote cache entry %v", err)
+		return false, nil
 	}
 
-	return false, nil
+	return true, nil
 }
@@ -2,8 +2,14 @@ package graph
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,11 +21,17 @@ import (
 	"github.com/myfintech/ark/src/go/lib/ark/cqrs"
 
 	"github.com/myfintech/ark/src/go/lib/ark/targets/docker_image"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/http_archive"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/jsonnet"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/local_exec"
 
 	"github.com/myfintech/ark/src/go/lib/ark/shared_clients"
 
 	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/derivation"
 	"github.com/myfintech/ark/src/go/lib/ark/storage/memory"
+	"github.com/myfintech/ark/src/go/lib/dag"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -67,7 +79,7 @@ func TestExecutionEngine(t *testing.T) {
 		Ctx:            ctx,
 		Store:          store,
 		SharedClients:  sharedClients,
-		RootTargetKey:  rootTarget.Key(),
+		RootTargetKeys: []string{rootTarget.Key()},
 		Broker:         new(cqrs.NoOpBroker),
 		SubscriptionID: "test",
 		Logger:         logger,
@@ -75,6 +87,121 @@ func TestExecutionEngine(t *testing.T) {
 	require.NoError(t, Execute(opts))
 }
 
+func TestExecute_cacheableTargets(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, os.Setenv("ARK_CACHE_HOME", t.TempDir()))
+	defer func() {
+		_ = os.Unsetenv("ARK_CACHE_HOME")
+	}()
+
+	realm := t.TempDir()
+
+	// execute walks the graph of a single target in a workspace without a remote cache
+	execute := func(target ark.RawTarget) error {
+		testStore := new(memory.Store)
+		_, err := testStore.AddTarget(target)
+		require.NoError(t, err)
+
+		return Execute(ExecuteOptions{
+			Ctx:            ctx,
+			Store:          testStore,
+			SharedClients:  &shared_clients.Container{Logger: logz.NoOpLogger{}},
+			RootTargetKeys: []string{target.Key()},
+			Broker:         new(cqrs.NoOpBroker),
+			SubscriptionID: "test",
+			Logger:         logz.NoOpLogger{},
+		})
+	}
+
+	t.Run("should execute a local_exec target on a cache miss and reuse its artifact", func(t *testing.T) {
+		target := ark.RawTarget{
+			Name:    "codegen",
+			Type:    local_exec.Type,
+			File:    filepath.Join(realm, "build.ts"),
+			Realm:   realm,
+			Outputs: []string{"gen.txt"},
+			Attributes: map[string]interface{}{
+				"command": "/bin/sh",
+				"args":    []interface{}{"-c", "echo run >> runs.log && echo generated > gen.txt"},
+			},
+		}
+
+		require.NoError(t, execute(target))
+		require.NoError(t, os.Remove(filepath.Join(realm, "gen.txt")))
+		require.NoError(t, execute(target))
+
+		runs, err := os.ReadFile(filepath.Join(realm, "runs.log"))
+		require.NoError(t, err)
+		require.Equal(t, "run\n", string(runs), "the second walk should use the cached artifact")
+
+		generated, err := os.ReadFile(filepath.Join(realm, "gen.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "generated\n", string(generated), "the cached outputs should be restored")
+	})
+
+	t.Run("should download an http_archive target on a cache miss and reuse its artifact", func(t *testing.T) {
+		contents := []byte("plain file")
+		var downloads int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&downloads, 1)
+			_, _ = w.Write(contents)
+		}))
+		defer server.Close()
+
+		checksum := sha256.Sum256(contents)
+		target := ark.RawTarget{
+			Name:  "archive",
+			Type:  http_archive.Type,
+			File:  filepath.Join(realm, "build.ts"),
+			Realm: realm,
+			Attributes: map[string]interface{}{
+				"url":    server.URL + "/tool.txt",
+				"sha256": hex.EncodeToString(checksum[:]),
+			},
+		}
+
+		require.NoError(t, execute(target))
+		require.NoError(t, execute(target))
+		require.Equal(t, int32(1), atomic.LoadInt32(&downloads), "the second walk should use the cached artifact")
+	})
+
+	t.Run("should render a jsonnet target again after a library changed", func(t *testing.T) {
+		dir := filepath.Join(realm, "manifests")
+		libPath := filepath.Join(dir, "lib", "greeting.libsonnet")
+		require.NoError(t, os.MkdirAll(filepath.Dir(libPath), 0755))
+		require.NoError(t, os.WriteFile(libPath, []byte(`{ greeting: "hello" }`), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "app.jsonnet"), []byte(`local lib = import "greeting.libsonnet";
+{ message: lib.greeting }`), 0644))
+
+		target := ark.RawTarget{
+			Name:  "manifests",
+			Type:  jsonnet.Type,
+			File:  filepath.Join(dir, "build.ts"),
+			Realm: realm,
+			Attributes: map[string]interface{}{
+				"files":       []interface{}{"app.jsonnet"},
+				"libraryDirs": []interface{}{"lib"},
+			},
+		}
+
+		artifactsDir, err := ark.ArtifactsDir()
+		require.NoError(t, err)
+		rendered := func() int {
+			entries, readErr := os.ReadDir(filepath.Join(artifactsDir, target.Name))
+			require.NoError(t, readErr)
+			return len(entries)
+		}
+
+		require.NoError(t, execute(target))
+		require.NoError(t, execute(target))
+		require.Equal(t, 1, rendered(), "the second walk should use the cached artifact")
+
+		require.NoError(t, os.WriteFile(libPath, []byte(`{ greeting: "hi" }`), 0644))
+		require.NoError(t, execute(target))
+		require.Equal(t, 2, rendered(), "editing a library should produce a new artifact")
+	})
+}
+
 func TestValidationWalk(t *testing.T) {
 	opts := ExecuteOptions{
 		K8sNamespace: "",
@@ -83,6 +210,348 @@ func TestValidationWalk(t *testing.T) {
 	require.Error(t, walkerFunc(&ark.RawTarget{Type: deploy.Type}))
 }
 
+func Test_selectRootVertices(t *testing.T) {
+	labelStore := new(memory.Store)
+	for name, labels := range map[string][]string{
+		"api":      {"team=payments", "image"},
+		"api-test": {"team=payments", "slow"},
+		"worker":   {"team=ledger", "image"},
+	} {
+		_, err := labelStore.AddTarget(ark.RawTarget{
+			Name:   name,
+			Type:   docker_image.Type,
+			File:   "test/build.ts",
+			Realm:  "test",
+			Labels: labels,
+		})
+		require.NoError(t, err)
+	}
+
+	roots, err := selectRootVertices(ExecuteOptions{
+		Store:  labelStore,
+		Labels: ark.NewLabelSelector([]string{"team=payments"}, []string{"slow"}),
+	})
+	require.NoError(t, err)
+	require.Len(t, roots, 1)
+	require.Equal(t, "build.ts:api", roots[0].(ark.RawTarget).Key())
+
+	roots, err = selectRootVertices(ExecuteOptions{
+		Store:          labelStore,
+		RootTargetKeys: []string{"build.ts:api", "build.ts:worker"},
+		Labels:         ark.NewLabelSelector([]string{"image"}, nil),
+	})
+	require.NoError(t, err)
+	require.Len(t, roots, 2)
+
+	_, err = selectRootVertices(ExecuteOptions{
+		Store:          labelStore,
+		RootTargetKeys: []string{"build.ts:api-test"},
+		Labels:         ark.NewLabelSelector([]string{"image"}, nil),
+	})
+	require.Error(t, err)
+}
+
+func Test_pruneSkipped(t *testing.T) {
+	newTarget := func(name string) ark.RawTarget {
+		return ark.RawTarget{Name: name, Type: docker_image.Type, File: "test/build.ts", Realm: "test"}
+	}
+	app, image, base, config := newTarget("app"), newTarget("image"), newTarget("base"), newTarget("config")
+
+	newGraph := func() *dag.AcyclicGraph {
+		g := new(dag.AcyclicGraph)
+		for _, target := range []ark.RawTarget{app, image, base, config} {
+			g.Add(target)
+		}
+		// app depends on image and config, image depends on base
+		g.Connect(dag.BasicEdge(app, image))
+		g.Connect(dag.BasicEdge(app, config))
+		g.Connect(dag.BasicEdge(image, base))
+		return g
+	}
+	roots := []dag.Vertex{app}
+
+	g, skipped, err := pruneSkipped(newGraph(), roots, nil, SkipModeFail)
+	require.NoError(t, err)
+	require.Empty(t, skipped)
+	require.Len(t, g.Vertices(), 4)
+
+	_, _, err = pruneSkipped(newGraph(), roots, []string{"image"}, SkipModeFail)
+	require.Error(t, err, "app depends on the skipped image")
+
+	g, skipped, err = pruneSkipped(newGraph(), roots, []string{"image"}, SkipModeLastKnown)
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{image.Key(): true}, skipped)
+	require.True(t, g.HasVertex(image))
+	require.False(t, g.HasVertex(base), "base is only reachable through the skipped image")
+	require.True(t, g.HasVertex(config))
+
+	g, skipped, err = pruneSkipped(newGraph(), roots, []string{"build.ts:app"}, SkipModeFail)
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{app.Key(): true}, skipped)
+	require.Len(t, g.Vertices(), 1)
+
+	_, err = ParseSkipMode("sometimes")
+	require.Error(t, err)
+}
+
+func Test_lastKnownArtifact(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, os.Setenv("ARK_CACHE_HOME", t.TempDir()))
+	defer func() {
+		_ = os.Unsetenv("ARK_CACHE_HOME")
+	}()
+
+	key := "test/build.ts:image"
+	current := &ark.RawArtifact{Key: key, Hash: "2222"}
+
+	lastKnown, err := lastKnownArtifact(ctx, key, current, logz.NoOpLogger{})
+	require.NoError(t, err)
+	require.Nil(t, lastKnown, "the target was never built")
+
+	require.NoError(t, ark.RawArtifact{Key: key, Hash: "1111"}.WriteState())
+
+	lastKnown, err = lastKnownArtifact(ctx, key, current, logz.NoOpLogger{})
+	require.NoError(t, err)
+	require.NotNil(t, lastKnown)
+	rawArtifact, err := derivation.RawArtifactFromArtifact(lastKnown)
+	require.NoError(t, err)
+	require.Equal(t, "1111", rawArtifact.Hash)
+}
+
+func Test_walkResults(t *testing.T) {
+	newTarget := func(name string) ark.RawTarget {
+		return ark.RawTarget{Name: name, Type: docker_image.Type, File: "test/build.ts", Realm: "test"}
+	}
+	app, image, base, docs := newTarget("app"), newTarget("image"), newTarget("base"), newTarget("docs")
+
+	g := new(dag.AcyclicGraph)
+	for _, target := range []ark.RawTarget{app, image, base, docs} {
+		g.Add(target)
+	}
+	// app depends on image which depends on base, docs is independent
+	g.Connect(dag.BasicEdge(app, image))
+	g.Connect(dag.BasicEdge(image, base))
+
+	t.Run("should not report a successful walk", func(t *testing.T) {
+		results := newWalkResults(false)
+		for _, target := range []ark.RawTarget{base, image, app, docs} {
+			results.visit(target.Key())
+		}
+		report, blocked, err := results.report(g)
+		require.NoError(t, err)
+		require.Nil(t, report)
+		require.Empty(t, blocked)
+	})
+
+	t.Run("should abort after the first failure", func(t *testing.T) {
+		results := newWalkResults(false)
+		results.visit(base.Key())
+		require.False(t, results.aborted())
+		results.fail(base.Key(), fmt.Errorf("exit status 1"))
+		require.True(t, results.aborted())
+	})
+
+	t.Run("should keep going and report failed and blocked targets", func(t *testing.T) {
+		results := newWalkResults(true)
+		results.visit(docs.Key())
+		results.visit(base.Key())
+		results.fail(base.Key(), fmt.Errorf("exit status 1"))
+		require.False(t, results.aborted())
+
+		report, blocked, err := results.report(g)
+		require.NoError(t, err)
+		require.Equal(t, []TargetFailure{{Key: base.Key(), Error: "exit status 1"}}, report.Failed)
+		require.Equal(t, []string{app.Key(), image.Key()}, report.Blocked)
+		require.Len(t, blocked, 2)
+		require.Contains(t, report.Error(), "1 target(s) failed and 2 target(s) were blocked")
+		require.Contains(t, report.Error(), "failed build.ts:base: exit status 1")
+	})
+}
+
+func Test_resourcePools(t *testing.T) {
+	pools := newResourcePools(map[string]int64{"docker": 2, "cpu": 4, "disabled": 0})
+
+	tryAcquire := func(weights map[string]int64) (func(), error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		return pools.acquire(ctx, weights)
+	}
+
+	releaseBuild, err := tryAcquire(map[string]int64{"docker": 2, "cpu": 1})
+	require.NoError(t, err)
+
+	_, err = tryAcquire(map[string]int64{"docker": 1})
+	require.Error(t, err, "the docker pool is exhausted")
+
+	releaseTest, err := tryAcquire(map[string]int64{"cpu": 3})
+	require.NoError(t, err, "the cpu pool still has capacity")
+
+	releaseUnlimited, err := tryAcquire(map[string]int64{"k8s": 10, "disabled": 5})
+	require.NoError(t, err, "pools without a capacity are not limited")
+	releaseUnlimited()
+
+	// a failed acquisition must not hold the pools it already acquired
+	_, err = tryAcquire(map[string]int64{"cpu": 1, "docker": 1})
+	require.Error(t, err)
+
+	releaseBuild()
+	releaseTest()
+
+	releaseHeavy, err := tryAcquire(map[string]int64{"docker": 10})
+	require.NoError(t, err, "a weight larger than the pool capacity is reduced to the capacity")
+	releaseHeavy()
+
+	release, err := tryAcquire(map[string]int64{"docker": 2, "cpu": 4})
+	require.NoError(t, err)
+	release()
+}
+
+// flakyAction fails until it has been executed a number of times
+type flakyAction struct {
+	failures int
+	attempts int
+	delay    time.Duration
+}
+
+func (f *flakyAction) Execute(ctx context.Context) error {
+	f.attempts++
+	if f.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(f.delay):
+		}
+	}
+	if f.attempts <= f.failures {
+		return fmt.Errorf("attempt %d failed", f.attempts)
+	}
+	return nil
+}
+
+// stubbornAction ignores the cancellation of its context and records whether attempts overlapped
+// it returns err once the delay elapsed
+type stubbornAction struct {
+	delay      time.Duration
+	err        error
+	attempts   int32
+	running    int32
+	overlapped int32
+}
+
+func (s *stubbornAction) Execute(_ context.Context) error {
+	atomic.AddInt32(&s.attempts, 1)
+	if atomic.AddInt32(&s.running, 1) > 1 {
+		atomic.StoreInt32(&s.overlapped, 1)
+	}
+	defer atomic.AddInt32(&s.running, -1)
+	time.Sleep(s.delay)
+	return s.err
+}
+
+func Test_executeWithPolicy(t *testing.T) {
+	ctx := context.Background()
+	var retries []int
+	onRetry := func(attempt int, err error) error {
+		retries = append(retries, attempt)
+		return nil
+	}
+
+	t.Run("should execute once by default", func(t *testing.T) {
+		retries = nil
+		action := &flakyAction{failures: 1}
+		attempts, err := executeWithPolicy(ctx, ark.ExecutionPolicy{}, action, onRetry)
+		require.Error(t, err)
+		require.Equal(t, 1, attempts)
+		require.Empty(t, retries)
+	})
+
+	t.Run("should retry until the action succeeds", func(t *testing.T) {
+		retries = nil
+		action := &flakyAction{failures: 2}
+		attempts, err := executeWithPolicy(ctx, ark.ExecutionPolicy{MaxAttempts: 5}, action, onRetry)
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+		require.Equal(t, []int{1, 2}, retries)
+	})
+
+	t.Run("should return the last error once the attempts are exhausted", func(t *testing.T) {
+		retries = nil
+		action := &flakyAction{failures: 5}
+		attempts, err := executeWithPolicy(ctx, ark.ExecutionPolicy{MaxAttempts: 2}, action, onRetry)
+		require.EqualError(t, err, "attempt 2 failed")
+		require.Equal(t, 2, attempts)
+		require.Equal(t, []int{1}, retries)
+	})
+
+	t.Run("should time out an attempt", func(t *testing.T) {
+		retries = nil
+		action := &flakyAction{delay: 5 * time.Second}
+		_, err := executeWithPolicy(ctx, ark.ExecutionPolicy{TimeoutSeconds: 1}, action, onRetry)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "timed out")
+	})
+
+	t.Run("should wait for a timed out attempt before retrying", func(t *testing.T) {
+		retries = nil
+		defer func(gracePeriod time.Duration) { attemptGracePeriod = gracePeriod }(attemptGracePeriod)
+		attemptGracePeriod = 5 * time.Second
+
+		action := &stubbornAction{delay: 1500 * time.Millisecond, err: errors.New("interrupted")}
+		attempts, err := executeWithPolicy(ctx, ark.ExecutionPolicy{MaxAttempts: 2, TimeoutSeconds: 1}, action, onRetry)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "interrupted")
+		require.Equal(t, 2, attempts)
+		require.Equal(t, int32(2), atomic.LoadInt32(&action.attempts))
+		require.Zero(t, atomic.LoadInt32(&action.overlapped), "a retry must not start while the timed out attempt is running")
+	})
+
+	t.Run("should keep the result of an attempt that returns during the grace period", func(t *testing.T) {
+		retries = nil
+		defer func(gracePeriod time.Duration) { attemptGracePeriod = gracePeriod }(attemptGracePeriod)
+		attemptGracePeriod = 5 * time.Second
+
+		action := &stubbornAction{delay: 1500 * time.Millisecond}
+		attempts, err := executeWithPolicy(ctx, ark.ExecutionPolicy{MaxAttempts: 2, TimeoutSeconds: 1}, action, onRetry)
+		require.NoError(t, err, "an attempt that succeeded late is a success")
+		require.Equal(t, 1, attempts)
+		require.Empty(t, retries)
+	})
+
+	t.Run("should not retry an attempt that ignores its cancellation", func(t *testing.T) {
+		retries = nil
+		defer func(gracePeriod time.Duration) { attemptGracePeriod = gracePeriod }(attemptGracePeriod)
+		attemptGracePeriod = 100 * time.Millisecond
+
+		action := &stubbornAction{delay: 3 * time.Second}
+		attempts, err := executeWithPolicy(ctx, ark.ExecutionPolicy{MaxAttempts: 3, TimeoutSeconds: 1}, action, onRetry)
+		require.True(t, errors.Is(err, errAttemptAbandoned))
+		require.Equal(t, 1, attempts)
+		require.Empty(t, retries)
+
+		running := runningAttempt(err)
+		require.NotNil(t, running, "the abandoned attempt is still running")
+		select {
+		case <-running:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the result of the abandoned attempt was never delivered")
+		}
+		require.Zero(t, atomic.LoadInt32(&action.running))
+	})
+
+	t.Run("should back off exponentially", func(t *testing.T) {
+		policy := ark.ExecutionPolicy{BackoffSeconds: 2}
+		require.Equal(t, 2*time.Second, policy.Backoff(1))
+		require.Equal(t, 4*time.Second, policy.Backoff(2))
+		require.Equal(t, 8*time.Second, policy.Backoff(3))
+		require.Zero(t, ark.ExecutionPolicy{}.Backoff(1))
+	})
+
+	t.Run("should reject a negative policy", func(t *testing.T) {
+		require.Error(t, ark.ExecutionPolicy{MaxAttempts: -1}.Validate())
+		require.NoError(t, ark.ExecutionPolicy{MaxAttempts: 3, BackoffSeconds: 1, TimeoutSeconds: 60}.Validate())
+	})
+}
+
 type mockAction struct {
 	Logger logz.FieldLogger
 }
@@ -125,3 +594,51 @@ func Test_injectOrSkipLogger(t *testing.T) {
 	input.action = iSureHopeThisDoesntPanic
 	require.NotPanics(t, func() { injectOrSkipLogger(input) })
 }
+
+func Test_sharedExecutions(t *testing.T) {
+	ctx := context.Background()
+	shared := NewSharedExecutions()
+	noWait := func(owner string) error {
+		return errors.Errorf("unexpected wait on %s", owner)
+	}
+
+	releaseFirst, err := shared.acquire(ctx, "abc123", "run-1", noWait)
+	require.NoError(t, err)
+
+	releaseOther, err := shared.acquire(ctx, "def456", "run-2", noWait)
+	require.NoError(t, err, "different hashes are executed concurrently")
+	releaseOther()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = shared.acquire(timeoutCtx, "abc123", "run-2", func(string) error { return nil })
+	require.Error(t, err, "a run waiting on a shared execution can be canceled")
+
+	awaited := make(chan string, 1)
+	acquired := make(chan error, 1)
+	go func() {
+		release, acquireErr := shared.acquire(ctx, "abc123", "run-3", func(owner string) error {
+			awaited <- owner
+			return nil
+		})
+		if acquireErr == nil {
+			release()
+		}
+		acquired <- acquireErr
+	}()
+
+	require.Equal(t, "run-1", <-awaited)
+	select {
+	case <-acquired:
+		t.Fatal("the hash was acquired while run-1 was executing it")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	releaseFirst()
+	require.NoError(t, <-acquired)
+
+	var nilShared *SharedExecutions
+	release, err := nilShared.acquire(ctx, "abc123", "run-4", noWait)
+	require.NoError(t, err, "a nil registry disables coalescing")
+	release()
+}
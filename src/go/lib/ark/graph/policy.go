@@ -0,0 +1,104 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+// retryFunc is called with the error of every failed attempt that will be retried
+type retryFunc func(attempt int, err error) error
+
+// executeWithPolicy executes an action until it succeeds or the attempts of the policy are exhausted
+// It returns the number of attempts and the error of the last attempt
+func executeWithPolicy(ctx context.Context, policy ark.ExecutionPolicy, action ark.Action, onRetry retryFunc) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := executeAttempt(ctx, policy.Timeout(), action)
+		if err == nil || attempt >= policy.Attempts() || ctx.Err() != nil || errors.Is(err, errAttemptAbandoned) {
+			return attempt, err
+		}
+
+		if retryErr := onRetry(attempt, err); retryErr != nil {
+			return attempt, retryErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		case <-time.After(policy.Backoff(attempt)):
+		}
+	}
+}
+
+// attemptGracePeriod the duration an attempt is waited for after its context was canceled by a timeout
+var attemptGracePeriod = 30 * time.Second
+
+// errAttemptAbandoned is returned when an attempt didn't return within the grace period after it timed out
+// the attempt may still be running so the action must not be retried
+var errAttemptAbandoned = errors.New("the action did not stop within the grace period after it was canceled")
+
+// abandonedAttempt the error of an attempt that was abandoned while it was still running
+// finished receives the result of the attempt once it returns
+type abandonedAttempt struct {
+	timeout  time.Duration
+	finished <-chan error
+}
+
+func (a *abandonedAttempt) Error() string {
+	return errors.Wrapf(errAttemptAbandoned, "the action timed out after %s", a.timeout).Error()
+}
+
+// Is reports the attempt as errAttemptAbandoned
+func (a *abandonedAttempt) Is(target error) bool {
+	return target == errAttemptAbandoned
+}
+
+// runningAttempt returns a channel that receives the result of an abandoned attempt once it returns
+// nil is returned if the error is not from an abandoned attempt
+func runningAttempt(err error) <-chan error {
+	var abandoned *abandonedAttempt
+	if errors.As(err, &abandoned) {
+		return abandoned.finished
+	}
+	return nil
+}
+
+// executeAttempt executes an action once, an action that doesn't return before the timeout is reported as failed
+// The context passed to the action is canceled on timeout and the attempt is waited for until the grace period ends
+// so a retry never executes concurrently with the attempt it replaces
+// The result of an attempt that returns during the grace period is kept, so an action that finished just after the timeout succeeds
+func executeAttempt(ctx context.Context, timeout time.Duration, action ark.Action) error {
+	if timeout <= 0 {
+		return action.Execute(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- action.Execute(attemptCtx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-attemptCtx.Done():
+	}
+
+	select {
+	case err := <-result:
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return errors.Wrapf(err, "the action timed out after %s", timeout)
+	case <-time.After(attemptGracePeriod):
+		return &abandonedAttempt{timeout: timeout, finished: result}
+	}
+}
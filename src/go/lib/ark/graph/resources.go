@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/semaphore"
+)
+
+// resourcePools limits the combined weight of the targets executing against each configured pool
+type resourcePools struct {
+	capacities map[string]int64
+	semaphores map[string]*semaphore.Weighted
+}
+
+func newResourcePools(capacities map[string]int64) *resourcePools {
+	pools := &resourcePools{
+		capacities: make(map[string]int64),
+		semaphores: make(map[string]*semaphore.Weighted),
+	}
+	for pool, capacity := range capacities {
+		if capacity < 1 {
+			continue
+		}
+		pools.capacities[pool] = capacity
+		pools.semaphores[pool] = semaphore.NewWeighted(capacity)
+	}
+	return pools
+}
+
+// acquire blocks until the weights can be acquired from every pool and returns a function that releases them
+// Pools are acquired in a stable order so targets waiting on several pools can't deadlock each other
+// A weight larger than the capacity of its pool is reduced to the capacity so the target can still run alone
+func (p *resourcePools) acquire(ctx context.Context, weights map[string]int64) (func(), error) {
+	var pools []string
+	for pool := range weights {
+		if _, ok := p.semaphores[pool]; ok {
+			pools = append(pools, pool)
+		}
+	}
+	sort.Strings(pools)
+
+	var acquired []func()
+	release := func() {
+		for i := len(acquired) - 1; i >= 0; i-- {
+			acquired[i]()
+		}
+	}
+
+	for _, pool := range pools {
+		weight := weights[pool]
+		if weight > p.capacities[pool] {
+			weight = p.capacities[pool]
+		}
+
+		sem := p.semaphores[pool]
+		if err := sem.Acquire(ctx, weight); err != nil {
+			release()
+			return nil, errors.Wrapf(err, "failed to acquire %d from resource pool %s", weight, pool)
+		}
+		acquired = append(acquired, func() {
+			sem.Release(weight)
+		})
+	}
+
+	return release, nil
+}
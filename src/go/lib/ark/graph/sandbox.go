@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/derivation"
+	"github.com/myfintech/ark/src/go/lib/dag"
+)
+
+// dependencyOutputs returns the absolute paths of the outputs declared by every transitive dependency of the vertex
+// by the time a vertex is walked the outputs of its dependencies were produced or restored into the workspace
+func dependencyOutputs(graph *dag.AcyclicGraph, vertex dag.Vertex) ([]string, error) {
+	ancestors, err := graph.Ancestors(vertex)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []string
+	for _, ancestor := range ancestors.List() {
+		var rawTarget ark.RawTarget
+		switch t := ancestor.(type) {
+		case ark.RawTarget:
+			rawTarget = t
+		case *ark.RawTarget:
+			rawTarget = *t
+		default:
+			return nil, errors.Errorf("%T is not type ark.RawTarget", ancestor)
+		}
+		if len(rawTarget.Outputs) == 0 {
+			continue
+		}
+
+		target, err := derivation.TargetFromRawTarget(rawTarget)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, outputsRooter(target, rawTarget).OutputPaths()...)
+	}
+	sort.Strings(outputs)
+	return outputs, nil
+}
+
+// outputsRooter returns the target that declares the directory the outputs are relative to
+// targets that don't embed RawTarget fall back to the directory of the build file
+func outputsRooter(target ark.Target, rawTarget ark.RawTarget) ark.OutputsRooter {
+	if rooter, ok := target.(ark.OutputsRooter); ok {
+		return rooter
+	}
+	return rawTarget
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"context"
+	"sync"
+)
+
+// SharedExecutions coalesces the executions of artifacts with the same hash across concurrent graph runs
+// A single instance is shared by every run of a graph runner, a nil *SharedExecutions disables coalescing
+type SharedExecutions struct {
+	mutex      sync.Mutex
+	executions map[string]*sharedExecution
+}
+
+type sharedExecution struct {
+	owner string
+	done  chan struct{}
+}
+
+// NewSharedExecutions creates an empty registry of in-flight executions
+func NewSharedExecutions() *SharedExecutions {
+	return &SharedExecutions{executions: make(map[string]*sharedExecution)}
+}
+
+// acquire claims the execution of an artifact hash for the run with the given subscription ID
+// If another run is executing the same hash onWait is called once with the subscription ID of that run
+// and acquire blocks until the execution finishes, the caller is expected to verify the artifact again after acquiring it
+func (s *SharedExecutions) acquire(ctx context.Context, hash, owner string, onWait func(owner string) error) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+
+	waited := false
+	for {
+		s.mutex.Lock()
+		execution, busy := s.executions[hash]
+		if !busy {
+			execution = &sharedExecution{owner: owner, done: make(chan struct{})}
+			s.executions[hash] = execution
+			s.mutex.Unlock()
+
+			return func() {
+				s.mutex.Lock()
+				delete(s.executions, hash)
+				s.mutex.Unlock()
+				close(execution.done)
+			}, nil
+		}
+		s.mutex.Unlock()
+
+		if !waited {
+			if err := onWait(execution.owner); err != nil {
+				return nil, err
+			}
+			waited = true
+		}
+
+		select {
+		case <-execution.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package graph
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/derivation"
+	"github.com/myfintech/ark/src/go/lib/ark/local_cache"
+	"github.com/myfintech/ark/src/go/lib/dag"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+// SkipMode determines how the dependents of a skipped target are walked
+type SkipMode string
+
+const (
+	// SkipModeFail refuses to walk a graph where a target that is not skipped depends on a skipped target
+	SkipModeFail SkipMode = "fail"
+
+	// SkipModeLastKnown walks the dependents of a skipped target against its last known artifact
+	// The artifact of its current sources is used if it is locally or remotely cached, otherwise the most recently used
+	// artifact of the target in the local cache is used. The dependents fail if the target was never built
+	// The action of the skipped target is never executed
+	SkipModeLastKnown SkipMode = "last-known"
+)
+
+// ParseSkipMode parses a skip mode, an empty mode is SkipModeFail
+func ParseSkipMode(mode string) (SkipMode, error) {
+	switch SkipMode(mode) {
+	case "", SkipModeFail:
+		return SkipModeFail, nil
+	case SkipModeLastKnown:
+		return SkipModeLastKnown, nil
+	default:
+		return "", errors.Errorf("%s is not a valid skip mode (%s|%s)", mode, SkipModeFail, SkipModeLastKnown)
+	}
+}
+
+// matchesSkipFilter returns true if any filter matches the key or the name of the target
+// Filters are glob patterns (e.g. *_test or src/go/services/api/build.ts:*)
+func matchesSkipFilter(target ark.RawTarget, filters []string) bool {
+	for _, filter := range filters {
+		if filter == target.Key() {
+			return true
+		}
+		if matched, _ := path.Match(filter, target.Name); matched {
+			return true
+		}
+		if matched, _ := path.Match(filter, target.Key()); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneSkipped removes the dependencies of skipped targets from the graph
+// along with every target that was only reachable through a skipped target
+// The skipped targets remain in the graph so the walker can report them
+// The keys of the skipped targets are returned
+func pruneSkipped(graph *dag.AcyclicGraph, roots []dag.Vertex, filters []string, mode SkipMode) (*dag.AcyclicGraph, map[string]bool, error) {
+	skipped := make(map[string]bool)
+	if len(filters) == 0 {
+		return graph, skipped, nil
+	}
+
+	for _, vertex := range graph.Vertices() {
+		rawTarget, err := rawTargetFromVertex(vertex)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !matchesSkipFilter(rawTarget, filters) {
+			continue
+		}
+		skipped[rawTarget.Key()] = true
+		for _, edge := range graph.EdgesFrom(vertex) {
+			graph.RemoveEdge(edge)
+		}
+	}
+
+	if len(skipped) == 0 {
+		return graph, skipped, nil
+	}
+
+	graph = graph.IsolateAll(roots...)
+
+	if mode != SkipModeFail {
+		return graph, skipped, nil
+	}
+
+	for _, vertex := range graph.Vertices() {
+		rawTarget, err := rawTargetFromVertex(vertex)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !skipped[rawTarget.Key()] {
+			continue
+		}
+
+		var dependents []string
+		for _, dependent := range graph.UpEdges(vertex).List() {
+			dependentTarget, castErr := rawTargetFromVertex(dependent)
+			if castErr != nil {
+				return nil, nil, castErr
+			}
+			if !skipped[dependentTarget.Key()] {
+				dependents = append(dependents, dependentTarget.Key())
+			}
+		}
+
+		if len(dependents) > 0 {
+			sort.Strings(dependents)
+			return nil, nil, errors.Errorf(
+				"%s is skipped but %s depend on it, skip its dependents or use the %s skip mode",
+				rawTarget.Key(), strings.Join(dependents, ", "), SkipModeLastKnown,
+			)
+		}
+	}
+
+	return graph, skipped, nil
+}
+
+// hasUnskippedDependents returns true if a target that is not skipped depends on the vertex
+func hasUnskippedDependents(graph *dag.AcyclicGraph, vertex dag.Vertex, skipped map[string]bool) (bool, error) {
+	for _, dependent := range graph.UpEdges(vertex).List() {
+		dependentTarget, err := rawTargetFromVertex(dependent)
+		if err != nil {
+			return false, err
+		}
+		if !skipped[dependentTarget.Key()] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// lastKnownArtifact returns the artifact the dependents of a skipped target are executed against
+// The artifact of the current sources is preferred, otherwise the most recently used artifact of the target in the local cache is returned
+// nil is returned if no artifact of the target was ever built
+func lastKnownArtifact(ctx context.Context, key string, artifact ark.Artifact, logger logz.FieldLogger) (ark.Artifact, error) {
+	available, err := verifyArtifact(ctx, artifact, logger)
+	if err != nil {
+		return nil, err
+	}
+	if available {
+		return artifact, nil
+	}
+
+	rawArtifact, err := derivation.RawArtifactFromArtifact(artifact)
+	if err != nil {
+		return nil, err
+	}
+
+	artifactsDir, err := ark.ArtifactsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := local_cache.Manager{Dir: artifactsDir}.Previous(key, rawArtifact.Hash)
+	if err != nil || previous == nil {
+		return nil, err
+	}
+	return previous, previous.Touch()
+}
+
+// the graph isolation function produces a graph of pointers
+// this allows us to support static copies and pointer data in the graph
+func rawTargetFromVertex(vertex dag.Vertex) (ark.RawTarget, error) {
+	switch t := vertex.(type) {
+	case ark.RawTarget:
+		return t, nil
+	case *ark.RawTarget:
+		return *t, nil
+	default:
+		return ark.RawTarget{}, errors.Errorf("graph walk cannot continue %T is not type ark.RawTarget", vertex)
+	}
+}
@@ -0,0 +1,137 @@
+package ark
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/mitchellh/hashstructure/v2"
+)
+
+// HashInputKind the part of RawTarget.Checksum an input contributes to
+type HashInputKind string
+
+const (
+	// HashInputAttribute a hashed field of the target or one of its attributes
+	HashInputAttribute HashInputKind = "attribute"
+
+	// HashInputSourceFile a source file of the target
+	HashInputSourceFile HashInputKind = "source_file"
+
+	// HashInputAncestor the artifact hash of a dependency of the target
+	HashInputAncestor HashInputKind = "ancestor"
+
+	// HashInputOutput an output declared by the target
+	HashInputOutput HashInputKind = "output"
+)
+
+// HashInputs the inputs RawTarget.Checksum mixed into the hash of an artifact
+// They are recorded in artifact.json so a cache miss can be explained by diffing them against a previous build
+// Inputs that a target type adds to the checksum in its Produce method are not recorded
+type HashInputs struct {
+	// Attributes maps the hashed fields of the target and its attributes to the hash of their value
+	Attributes map[string]string `json:"attributes"`
+
+	// SourceFiles maps the source files relative to the realm to the sha256 of their contents
+	SourceFiles map[string]string `json:"sourceFiles"`
+
+	// Ancestors maps the keys of the dependencies to the hash of their artifact
+	Ancestors map[string]string `json:"ancestors"`
+
+	Outputs []string `json:"outputs"`
+}
+
+func newHashInputs() HashInputs {
+	return HashInputs{
+		Attributes:  map[string]string{},
+		SourceFiles: map[string]string{},
+		Ancestors:   map[string]string{},
+	}
+}
+
+// HashInputChange an input that is different between two builds of a target
+type HashInputChange struct {
+	Kind     HashInputKind `json:"kind"`
+	Name     string        `json:"name"`
+	Previous string        `json:"previous,omitempty"`
+	Current  string        `json:"current,omitempty"`
+}
+
+// Change returns a short description of the change
+func (c HashInputChange) Change() string {
+	switch {
+	case c.Previous == "":
+		return "added"
+	case c.Current == "":
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// Diff returns the inputs that changed since the previous build sorted by kind and name
+func (h HashInputs) Diff(previous HashInputs) []HashInputChange {
+	var changes []HashInputChange
+	changes = append(changes, diffHashInputMaps(HashInputAttribute, previous.Attributes, h.Attributes)...)
+	changes = append(changes, diffHashInputMaps(HashInputSourceFile, previous.SourceFiles, h.SourceFiles)...)
+	changes = append(changes, diffHashInputMaps(HashInputAncestor, previous.Ancestors, h.Ancestors)...)
+	changes = append(changes, diffHashInputMaps(HashInputOutput, outputsSet(previous.Outputs), outputsSet(h.Outputs))...)
+	return changes
+}
+
+// HashInputsRecorder an artifact that records the inputs of its hash when its state is written
+type HashInputsRecorder interface {
+	RecordHashInputs(inputs HashInputs)
+}
+
+// RecordHashInputs stores the inputs of the artifact hash so they are written to artifact.json
+func (r *RawArtifact) RecordHashInputs(inputs HashInputs) {
+	r.Inputs = &inputs
+}
+
+// recordAttributes records the fields of the target that are covered by its struct hash
+// this must list every RawTarget field that isn't tagged with hash:"-"
+func (t *RawTarget) recordAttributes(inputs *HashInputs) error {
+	fields := map[string]interface{}{
+		"name":                     t.Name,
+		"type":                     t.Type,
+		"ignoreFileNotExistsError": t.IgnoreFileNotExistsError,
+	}
+	for key, value := range t.Attributes {
+		fields["attributes."+key] = value
+	}
+
+	for name, value := range fields {
+		valueHash, err := hashstructure.Hash(value, hashstructure.FormatV2, nil)
+		if err != nil {
+			return err
+		}
+		inputs.Attributes[name] = strconv.FormatUint(valueHash, 16)
+	}
+	return nil
+}
+
+func diffHashInputMaps(kind HashInputKind, previous, current map[string]string) []HashInputChange {
+	var changes []HashInputChange
+	for name, value := range current {
+		if previous[name] != value {
+			changes = append(changes, HashInputChange{Kind: kind, Name: name, Previous: previous[name], Current: value})
+		}
+	}
+	for name, value := range previous {
+		if _, exists := current[name]; !exists {
+			changes = append(changes, HashInputChange{Kind: kind, Name: name, Previous: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+func outputsSet(outputs []string) map[string]string {
+	set := map[string]string{}
+	for _, output := range outputs {
+		set[output] = output
+	}
+	return set
+}
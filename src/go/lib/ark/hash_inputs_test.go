@@ -0,0 +1,80 @@
+package ark
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashInputs(t *testing.T) {
+	realm := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(realm, "main.go"), []byte("package main"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(realm, "util.go"), []byte("package main"), 0644))
+
+	newTarget := func() RawTarget {
+		target := RawTarget{
+			Name:        "build",
+			Type:        "test",
+			Realm:       realm,
+			File:        filepath.Join(realm, "build.ts"),
+			SourceFiles: []string{"main.go", "util.go"},
+			Attributes:  map[string]interface{}{"command": []interface{}{"go", "build"}},
+			DependsOn:   Ancestors{{Key: "lib/build.ts:lib", Hash: "aaa"}},
+			Outputs:     []string{"bin"},
+		}
+		require.NoError(t, target.Validate())
+		return target
+	}
+
+	previous := newTarget()
+	_, previousInputs, err := previous.ChecksumInputs()
+	require.NoError(t, err)
+
+	t.Run("should record the inputs of the checksum", func(t *testing.T) {
+		target := newTarget()
+		checksum, inputs, err := target.ChecksumInputs()
+		require.NoError(t, err)
+
+		plainChecksum, err := target.Checksum()
+		require.NoError(t, err)
+		require.Equal(t, hex.EncodeToString(plainChecksum.Sum(nil)), hex.EncodeToString(checksum.Sum(nil)))
+
+		require.Contains(t, inputs.Attributes, "name")
+		require.Contains(t, inputs.Attributes, "attributes.command")
+		require.Len(t, inputs.SourceFiles, 2)
+		require.Equal(t, map[string]string{"lib/build.ts:lib": "aaa"}, inputs.Ancestors)
+		require.Equal(t, []string{"bin"}, inputs.Outputs)
+		require.Empty(t, inputs.Diff(previousInputs))
+	})
+
+	t.Run("should report every changed input", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(realm, "main.go"), []byte("package main\n"), 0644))
+		defer func() {
+			require.NoError(t, os.WriteFile(filepath.Join(realm, "main.go"), []byte("package main"), 0644))
+		}()
+
+		target := newTarget()
+		target.Attributes["command"] = []interface{}{"go", "build", "-race"}
+		target.Attributes["environment"] = map[string]interface{}{"CGO_ENABLED": "0"}
+		target.DependsOn = Ancestors{{Key: "lib/build.ts:lib", Hash: "bbb"}}
+		target.Outputs = nil
+
+		_, inputs, err := target.ChecksumInputs()
+		require.NoError(t, err)
+
+		var changes []string
+		for _, change := range inputs.Diff(previousInputs) {
+			changes = append(changes, string(change.Kind)+" "+change.Name+" "+change.Change())
+		}
+		require.Equal(t, []string{
+			"attribute attributes.command changed",
+			"attribute attributes.environment added",
+			"source_file main.go changed",
+			"ancestor lib/build.ts:lib changed",
+			"output bin removed",
+		}, changes)
+	})
+}
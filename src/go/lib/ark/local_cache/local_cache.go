@@ -0,0 +1,299 @@
+package local_cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/workspace"
+)
+
+// Entry represents a single artifact stored in the local cache
+type Entry struct {
+	Name       string    `json:"name"`
+	Hash       string    `json:"hash"`
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	LastAccess time.Time `json:"lastAccess"`
+}
+
+// Stats summarizes the contents of the local cache
+type Stats struct {
+	Dir        string        `json:"dir"`
+	Entries    int           `json:"entries"`
+	Size       int64         `json:"size"`
+	MaxSize    int64         `json:"maxSize"`
+	MaxAge     time.Duration `json:"maxAge"`
+	OldestUsed time.Time     `json:"oldestUsed"`
+	NewestUsed time.Time     `json:"newestUsed"`
+}
+
+// GCResult the entries evicted by a garbage collection
+type GCResult struct {
+	Evicted []Entry `json:"evicted"`
+	Freed   int64   `json:"freed"`
+}
+
+// Manager enforces size and age limits on the local artifact cache
+// A limit with a zero value is disabled
+type Manager struct {
+	Dir     string
+	MaxSize int64
+	MaxAge  time.Duration
+	Now     func() time.Time
+}
+
+// NewManager creates a cache manager for the default artifacts directory using the limits in the workspace config
+func NewManager(config workspace.Config) (*Manager, error) {
+	dir, err := ark.ArtifactsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var maxAge time.Duration
+	if config.LocalCache.MaxAge != "" {
+		maxAge, err = time.ParseDuration(config.LocalCache.MaxAge)
+		if err != nil {
+			return nil, errors.Wrap(err, "local_cache.max_age is not a valid duration")
+		}
+	}
+
+	if config.LocalCache.MaxSizeMB < 0 {
+		return nil, errors.New("local_cache.max_size_mb cannot be negative")
+	}
+
+	return &Manager{
+		Dir:     dir,
+		MaxSize: config.LocalCache.MaxSizeMB * 1024 * 1024,
+		MaxAge:  maxAge,
+		Now:     time.Now,
+	}, nil
+}
+
+// Limited returns true if the manager has a size or age limit to enforce
+func (m Manager) Limited() bool {
+	return m.MaxSize > 0 || m.MaxAge > 0
+}
+
+// Entries returns every artifact in the local cache sorted from least to most recently used
+// artifacts that are still being pulled into their staging directory are not entries
+func (m Manager) Entries() ([]Entry, error) {
+	var entries []Entry
+
+	names, err := os.ReadDir(m.Dir)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return entries, err
+	}
+
+	for _, name := range names {
+		if !name.IsDir() {
+			continue
+		}
+
+		hashes, err := os.ReadDir(filepath.Join(m.Dir, name.Name()))
+		if err != nil {
+			return entries, err
+		}
+
+		for _, hash := range hashes {
+			if !hash.IsDir() || strings.HasSuffix(hash.Name(), ark.ArtifactStagingSuffix) {
+				continue
+			}
+
+			entry, err := m.entry(name.Name(), hash)
+			if err != nil {
+				return entries, err
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastAccess.Before(entries[j].LastAccess)
+	})
+
+	return entries, nil
+}
+
+func (m Manager) entry(name string, hash os.DirEntry) (Entry, error) {
+	info, err := hash.Info()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry := Entry{
+		Name:       name,
+		Hash:       hash.Name(),
+		Path:       filepath.Join(m.Dir, name, hash.Name()),
+		LastAccess: info.ModTime(),
+	}
+
+	err = filepath.Walk(entry.Path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			entry.Size += info.Size()
+		}
+		return nil
+	})
+
+	return entry, err
+}
+
+// Stats returns a summary of the local cache
+func (m Manager) Stats() (Stats, error) {
+	stats := Stats{
+		Dir:     m.Dir,
+		MaxSize: m.MaxSize,
+		MaxAge:  m.MaxAge,
+	}
+
+	entries, err := m.Entries()
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Entries = len(entries)
+	for _, entry := range entries {
+		stats.Size += entry.Size
+	}
+
+	if len(entries) > 0 {
+		stats.OldestUsed = entries[0].LastAccess
+		stats.NewestUsed = entries[len(entries)-1].LastAccess
+	}
+
+	return stats, nil
+}
+
+// GC evicts every artifact that exceeds the max age and then evicts the least recently used artifacts until the cache fits the max size
+// If dryRun is true the evicted entries are reported but not removed
+func (m Manager) GC(dryRun bool) (GCResult, error) {
+	var result GCResult
+
+	entries, err := m.Entries()
+	if err != nil {
+		return result, err
+	}
+
+	var size int64
+	for _, entry := range entries {
+		size += entry.Size
+	}
+
+	now := time.Now
+	if m.Now != nil {
+		now = m.Now
+	}
+
+	for _, entry := range entries {
+		expired := m.MaxAge > 0 && now().Sub(entry.LastAccess) > m.MaxAge
+		oversized := m.MaxSize > 0 && size > m.MaxSize
+		if !expired && !oversized {
+			continue
+		}
+
+		if !dryRun {
+			evicted, err := m.Evict(entry)
+			if err != nil {
+				return result, err
+			}
+			if !evicted {
+				continue
+			}
+		}
+
+		size -= entry.Size
+		result.Freed += entry.Size
+		result.Evicted = append(result.Evicted, entry)
+	}
+
+	return result, nil
+}
+
+// Evict removes an entry from the local cache
+// An entry that was used after it was listed is kept and false is returned
+func (m Manager) Evict(entry Entry) (bool, error) {
+	info, err := os.Stat(entry.Path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().After(entry.LastAccess) {
+		return false, nil
+	}
+
+	if err = os.RemoveAll(entry.Path); err != nil {
+		return false, errors.Wrapf(err, "failed to evict %s", entry.Path)
+	}
+	// the parent directory is only removed if this was the last artifact for the name
+	_ = os.Remove(filepath.Dir(entry.Path))
+	return true, nil
+}
+
+// Previous returns the most recently used artifact of the target key whose hash is not the given hash
+// nil is returned if no other artifact of the key is in the local cache
+func (m Manager) Previous(key, hash string) (*ark.RawArtifact, error) {
+	parsedKey, err := ark.ParseKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes, err := os.ReadDir(filepath.Join(m.Dir, parsedKey.Name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *ark.RawArtifact
+	var lastAccess time.Time
+	for _, entry := range hashes {
+		if !entry.IsDir() || entry.Name() == hash || strings.HasSuffix(entry.Name(), ark.ArtifactStagingSuffix) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil && !info.ModTime().After(lastAccess) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(m.Dir, parsedKey.Name, entry.Name(), "artifact.json"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		artifact := new(ark.RawArtifact)
+		if err = json.Unmarshal(data, artifact); err != nil {
+			return nil, errors.Wrapf(err, "failed to read the cached artifact %s", entry.Name())
+		}
+
+		// artifacts are cached by target name so targets with the same name in different build files share a directory
+		if artifact.Key != key {
+			continue
+		}
+
+		previous = artifact
+		lastAccess = info.ModTime()
+	}
+	return previous, nil
+}
@@ -0,0 +1,132 @@
+package local_cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+func writeEntry(t *testing.T, dir, name, hash string, size int, lastAccess time.Time) string {
+	entryDir := filepath.Join(dir, name, hash)
+	require.NoError(t, os.MkdirAll(entryDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(entryDir, "artifact.json"), make([]byte, size), 0644))
+	require.NoError(t, os.Chtimes(entryDir, lastAccess, lastAccess))
+	return entryDir
+}
+
+func TestManager(t *testing.T) {
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	testdata := filepath.Join(cwd, "testdata")
+	defer func() {
+		_ = os.RemoveAll(testdata)
+	}()
+
+	now := time.Now()
+	oldest := writeEntry(t, testdata, "foo", "1111", 100, now.Add(-time.Hour*72))
+	older := writeEntry(t, testdata, "foo", "2222", 100, now.Add(-time.Hour*2))
+	newest := writeEntry(t, testdata, "bar", "3333", 100, now)
+	staging := writeEntry(t, testdata, "bar", "4444"+ark.ArtifactStagingSuffix, 100, now.Add(-time.Hour*96))
+
+	manager := Manager{
+		Dir: testdata,
+		Now: func() time.Time { return now },
+	}
+
+	t.Run("should report stats sorted by last access", func(t *testing.T) {
+		entries, err := manager.Entries()
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		require.Equal(t, oldest, entries[0].Path)
+		require.Equal(t, newest, entries[2].Path)
+
+		stats, err := manager.Stats()
+		require.NoError(t, err)
+		require.Equal(t, 3, stats.Entries)
+		require.Equal(t, int64(300), stats.Size)
+	})
+
+	t.Run("should not evict anything without limits", func(t *testing.T) {
+		result, err := manager.GC(false)
+		require.NoError(t, err)
+		require.Empty(t, result.Evicted)
+	})
+
+	t.Run("should evict entries older than the max age", func(t *testing.T) {
+		manager.MaxAge = time.Hour * 24
+
+		result, err := manager.GC(true)
+		require.NoError(t, err)
+		require.Len(t, result.Evicted, 1)
+		require.DirExists(t, oldest, "dry run should not remove entries")
+
+		result, err = manager.GC(false)
+		require.NoError(t, err)
+		require.Len(t, result.Evicted, 1)
+		require.Equal(t, int64(100), result.Freed)
+		require.NoDirExists(t, oldest)
+	})
+
+	t.Run("should evict the least recently used entries above the max size", func(t *testing.T) {
+		manager.MaxSize = 150
+
+		result, err := manager.GC(false)
+		require.NoError(t, err)
+		require.Len(t, result.Evicted, 1)
+		require.NoDirExists(t, older)
+		require.NoDirExists(t, filepath.Dir(older))
+		require.DirExists(t, newest)
+		require.DirExists(t, staging, "an artifact that is being pulled must never be evicted")
+	})
+
+	t.Run("should keep entries that were used after they were listed", func(t *testing.T) {
+		entries, err := manager.Entries()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		used := now.Add(time.Minute)
+		require.NoError(t, os.Chtimes(newest, used, used))
+
+		evicted, err := manager.Evict(entries[0])
+		require.NoError(t, err)
+		require.False(t, evicted)
+		require.DirExists(t, newest)
+	})
+}
+
+func TestManager_Previous(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	writeArtifact := func(name, hash, key string, lastAccess time.Time) {
+		entryDir := filepath.Join(dir, name, hash)
+		require.NoError(t, os.MkdirAll(entryDir, 0755))
+		data, err := json.Marshal(ark.RawArtifact{Key: key, Hash: hash})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(entryDir, "artifact.json"), data, 0644))
+		require.NoError(t, os.Chtimes(entryDir, lastAccess, lastAccess))
+	}
+
+	writeArtifact("build", "1111", "a/build.ts:build", now.Add(-time.Hour*2))
+	writeArtifact("build", "2222", "a/build.ts:build", now.Add(-time.Hour))
+	writeArtifact("build", "3333", "b/build.ts:build", now)
+	writeArtifact("build", "4444", "a/build.ts:build", now)
+
+	manager := Manager{Dir: dir}
+
+	previous, err := manager.Previous("a/build.ts:build", "4444")
+	require.NoError(t, err)
+	require.NotNil(t, previous)
+	require.Equal(t, "2222", previous.Hash)
+
+	previous, err = manager.Previous("a/build.ts:missing", "4444")
+	require.NoError(t, err)
+	require.Nil(t, previous)
+}
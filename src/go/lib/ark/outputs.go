@@ -0,0 +1,156 @@
+package ark
+
+import (
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/fs"
+)
+
+// outputsDirName the directory in the local artifact cache that holds the captured outputs of a target
+const outputsDirName = "outputs"
+
+// OutputsDir returns the location of the captured outputs in the local artifact cache
+func (r RawArtifact) OutputsDir() (string, error) {
+	cacheDir, err := r.CacheDirPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, outputsDirName), nil
+}
+
+// CaptureOutputs copies the declared outputs from dir into the local artifact cache
+// The outputs are archived with the artifact when it is pushed to a remote cache
+func (r RawArtifact) CaptureOutputs(dir string, outputs []string) error {
+	outputsDir, err := r.OutputsDir()
+	if err != nil {
+		return err
+	}
+
+	if err = os.RemoveAll(outputsDir); err != nil {
+		return err
+	}
+
+	for _, output := range outputs {
+		if err = copyTree(filepath.Join(dir, output), filepath.Join(outputsDir, output)); err != nil {
+			return errors.Wrapf(err, "failed to capture output %s", output)
+		}
+	}
+	return nil
+}
+
+// RestoreOutputs replaces the declared outputs in dir with the copies captured in the local artifact cache
+// Every captured copy is checked before anything is replaced, the outputs in dir are left untouched
+// when the artifact doesn't hold a copy of one of them
+func (r RawArtifact) RestoreOutputs(dir string, outputs []string) error {
+	outputsDir, err := r.OutputsDir()
+	if err != nil {
+		return err
+	}
+
+	for _, output := range outputs {
+		if _, err = os.Stat(filepath.Join(outputsDir, output)); err != nil {
+			return errors.Wrapf(err, "artifact %s does not hold a copy of output %s", r.Key, output)
+		}
+	}
+
+	for _, output := range outputs {
+		dest := filepath.Join(dir, output)
+		if err = os.RemoveAll(dest); err != nil {
+			return err
+		}
+		if err = copyTree(filepath.Join(outputsDir, output), dest); err != nil {
+			return errors.Wrapf(err, "failed to restore output %s", output)
+		}
+	}
+	return nil
+}
+
+// OutputsRooter a target that declares the directory its outputs are relative to
+// Every target that embeds RawTarget implements it and may override the directory of the build file
+type OutputsRooter interface {
+	OutputsRoot() string
+	OutputPaths() []string
+}
+
+// OutputsRoot returns the directory the declared outputs are relative to
+func (t RawTarget) OutputsRoot() string {
+	return t.Dir()
+}
+
+// OutputPaths returns the absolute paths of the declared outputs
+func (t RawTarget) OutputPaths() []string {
+	return OutputPaths(t.OutputsRoot(), t.Outputs)
+}
+
+// OutputPaths returns the absolute paths of outputs declared relative to root
+func OutputPaths(root string, outputs []string) []string {
+	var paths []string
+	for _, output := range outputs {
+		paths = append(paths, filepath.Join(root, output))
+	}
+	return paths
+}
+
+// validateOutputs outputs are relative to the root of the outputs of the target and can't escape it
+func (t RawTarget) validateOutputs() error {
+	for idx, output := range t.Outputs {
+		if output == "" {
+			return errors.Errorf("output at idx[%d] of target %s cannot be empty", idx, t.Key())
+		}
+		if filepath.IsAbs(output) {
+			return errors.Errorf("output %s of target %s must be a relative path", output, t.Key())
+		}
+		if clean := filepath.Clean(output); clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return errors.Errorf("output %s of target %s must not escape the directory it is relative to", output, t.Key())
+		}
+	}
+	return nil
+}
+
+// hashOutputs adds the declared outputs to the hash so an artifact always contains the outputs it declares
+// targets without outputs keep the hash they had before outputs could be declared
+func (t *RawTarget) hashOutputs(rootHash hash.Hash, inputs *HashInputs) error {
+	outputs := append([]string{}, t.Outputs...)
+	sort.Strings(outputs)
+	for _, output := range outputs {
+		if _, err := fmt.Fprintf(rootHash, "output:%s\n", filepath.Clean(output)); err != nil {
+			return err
+		}
+		inputs.Outputs = append(inputs.Outputs, filepath.Clean(output))
+	}
+	return nil
+}
+
+// copyTree copies a file or a directory recursively preserving file modes
+func copyTree(src, dest string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err = fs.Copy(path, target); err != nil {
+			return err
+		}
+		return os.Chmod(target, info.Mode().Perm())
+	})
+}
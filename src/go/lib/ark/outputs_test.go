@@ -0,0 +1,91 @@
+package ark
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputs(t *testing.T) {
+	realm := t.TempDir()
+	require.NoError(t, os.Setenv("ARK_CACHE_HOME", t.TempDir()))
+	defer func() {
+		_ = os.Unsetenv("ARK_CACHE_HOME")
+	}()
+
+	target := RawTarget{
+		Name:    "codegen",
+		Type:    "test",
+		Realm:   realm,
+		File:    filepath.Join(realm, "build.ts"),
+		Outputs: []string{"gen", "version.txt"},
+	}
+	require.NoError(t, target.Validate())
+
+	t.Run("should include outputs in the checksum", func(t *testing.T) {
+		withOutputs, err := target.Checksum()
+		require.NoError(t, err)
+
+		withoutOutputs := target
+		withoutOutputs.Outputs = nil
+		checksum, err := withoutOutputs.Checksum()
+		require.NoError(t, err)
+
+		require.NotEqual(t, hex.EncodeToString(checksum.Sum(nil)), hex.EncodeToString(withOutputs.Sum(nil)))
+	})
+
+	t.Run("should reject outputs outside of the build file directory", func(t *testing.T) {
+		for _, output := range []string{"", ".", "..", "../escape", "/etc/passwd"} {
+			invalid := target
+			invalid.Outputs = []string{output}
+			require.Error(t, invalid.Validate(), output)
+		}
+	})
+
+	t.Run("should capture and restore outputs", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(filepath.Join(realm, "gen", "nested"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(realm, "gen", "nested", "api.go"), []byte("package api"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(realm, "version.txt"), []byte("1.0.0"), 0755))
+
+		artifact := RawArtifact{Key: target.Key(), Hash: "abc123"}
+		require.NoError(t, artifact.CaptureOutputs(target.Dir(), target.Outputs))
+
+		// simulate a clean checkout on another machine
+		require.NoError(t, os.RemoveAll(filepath.Join(realm, "gen")))
+		require.NoError(t, os.WriteFile(filepath.Join(realm, "version.txt"), []byte("stale"), 0644))
+
+		require.NoError(t, artifact.RestoreOutputs(target.Dir(), target.Outputs))
+
+		api, err := os.ReadFile(filepath.Join(realm, "gen", "nested", "api.go"))
+		require.NoError(t, err)
+		require.Equal(t, "package api", string(api))
+
+		version, err := os.ReadFile(filepath.Join(realm, "version.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "1.0.0", string(version))
+
+		info, err := os.Stat(filepath.Join(realm, "version.txt"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	})
+
+	t.Run("should not replace outputs the artifact holds no copy of", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(realm, "version.txt"), []byte("edited"), 0644))
+
+		artifact := RawArtifact{Key: target.Key(), Hash: "789abc"}
+		require.Error(t, artifact.RestoreOutputs(target.Dir(), target.Outputs))
+
+		version, err := os.ReadFile(filepath.Join(realm, "version.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "edited", string(version))
+		require.DirExists(t, filepath.Join(realm, "gen"))
+	})
+
+	t.Run("should fail to capture missing outputs", func(t *testing.T) {
+		artifact := RawArtifact{Key: target.Key(), Hash: "def456"}
+		require.Error(t, artifact.CaptureOutputs(target.Dir(), []string{"missing.txt"}))
+	})
+}
@@ -0,0 +1,172 @@
+package ark
+
+import (
+	"database/sql/driver"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/myfintech/ark/src/go/lib/gorm/json_datatypes"
+)
+
+// RunStatus the state of a graph run or of a target within a run
+type RunStatus string
+
+const (
+	// RunStatusQueued the target has been derived but its action has not started
+	RunStatusQueued RunStatus = "queued"
+
+	// RunStatusRunning the run or the action of the target is executing
+	RunStatusRunning RunStatus = "running"
+
+	// RunStatusCached the artifact of the target was found in a cache and its action was not executed
+	RunStatusCached RunStatus = "cached"
+
+	// RunStatusSkipped the target matched a skip filter and its action was not executed
+	RunStatusSkipped RunStatus = "skipped"
+
+	// RunStatusSuccess the run or the action of the target completed successfully
+	RunStatusSuccess RunStatus = "success"
+
+	// RunStatusFailed the run or the action of the target failed
+	RunStatusFailed RunStatus = "failed"
+
+	// RunStatusFailureAllowed the action of the target failed but its execution policy allows failure
+	RunStatusFailureAllowed RunStatus = "failure_allowed"
+
+	// RunStatusBlocked the action of the target was not executed because a target failed
+	RunStatusBlocked RunStatus = "blocked"
+)
+
+// Done returns true if the status is terminal
+func (s RunStatus) Done() bool {
+	switch s {
+	case RunStatusCached, RunStatusSkipped, RunStatusSuccess, RunStatusFailed, RunStatusFailureAllowed, RunStatusBlocked:
+		return true
+	default:
+		return false
+	}
+}
+
+// RunStore persists the history of graph runs
+type RunStore interface {
+	SaveRun(run Run) error
+	GetRuns() ([]Run, error)
+	GetRunByID(id string) (Run, error)
+}
+
+// Run a record of a graph execution and the outcome of every target it walked
+// The ID of a run is the subscription ID returned when the run was requested
+type Run struct {
+	ID             string                     `json:"id" gorm:"primaryKey"`
+	TargetKeys     json_datatypes.StringSlice `json:"targetKeys"`
+	MaxConcurrency int                        `json:"maxConcurrency"`
+	Status         RunStatus                  `json:"status"`
+	Error          string                     `json:"error,omitempty"`
+	StartedAt      time.Time                  `json:"startedAt"`
+	FinishedAt     time.Time                  `json:"finishedAt"`
+	Targets        RunTargets                 `json:"targets"`
+
+	// targetIndex the position of every target in Targets by key
+	// indexedTargets the first element of Targets when the index was built, it changes when Targets is replaced
+	targetIndex    map[string]int
+	indexedTargets *RunTarget
+}
+
+// Duration returns the wall time of the run or zero if it has not finished
+func (r Run) Duration() time.Duration {
+	if r.FinishedAt.IsZero() || r.StartedAt.IsZero() {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Target returns the record of a target in the run, creating it if it doesn't exist
+// The targets are indexed by key, the index is rebuilt if Targets was replaced since it was built
+func (r *Run) Target(key string) *RunTarget {
+	if len(r.targetIndex) != len(r.Targets) || (len(r.Targets) > 0 && r.indexedTargets != &r.Targets[0]) {
+		r.targetIndex = make(map[string]int, len(r.Targets))
+		for i := range r.Targets {
+			r.targetIndex[r.Targets[i].Key] = i
+		}
+		r.indexedTargets = nil
+		if len(r.Targets) > 0 {
+			r.indexedTargets = &r.Targets[0]
+		}
+	}
+
+	if i, ok := r.targetIndex[key]; ok {
+		return &r.Targets[i]
+	}
+
+	r.Targets = append(r.Targets, RunTarget{
+		Key:    key,
+		Status: RunStatusQueued,
+	})
+	r.targetIndex[key] = len(r.Targets) - 1
+	r.indexedTargets = &r.Targets[0]
+	return &r.Targets[len(r.Targets)-1]
+}
+
+// RunTarget a record of a single target walked during a run
+type RunTarget struct {
+	Key        string    `json:"key"`
+	Hash       string    `json:"hash"`
+	Type       string    `json:"type"`
+	Status     RunStatus `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Attempts   int       `json:"attempts,omitempty"`
+	QueuedAt   time.Time `json:"queuedAt"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// Cached returns true if the action of the target was skipped because its artifact was cached
+func (t RunTarget) Cached() bool {
+	return t.Status == RunStatusCached
+}
+
+// Flaky returns true if the action of the target succeeded after being retried
+func (t RunTarget) Flaky() bool {
+	return t.Status == RunStatusSuccess && t.Attempts > 1
+}
+
+// Duration returns the wall time the graph walker spent on the target or zero if it did not finish
+func (t RunTarget) Duration() time.Duration {
+	if t.FinishedAt.IsZero() || t.StartedAt.IsZero() {
+		return 0
+	}
+	return t.FinishedAt.Sub(t.StartedAt)
+}
+
+// RunTargets a slice of targets walked during a run
+type RunTargets []RunTarget
+
+// Value return json value, implement driver.Valuer interface
+func (m RunTargets) Value() (driver.Value, error) {
+	return json_datatypes.MarshalString(&m)
+}
+
+// Scan scan value into Jsonb, implements sql.Scanner interface
+func (m *RunTargets) Scan(val interface{}) error {
+	return json_datatypes.Scan(val, m)
+}
+
+// GormDataType gorm common data type
+func (m RunTargets) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType gorm db data type
+func (RunTargets) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	return json_datatypes.DetermineDBDataType(db)
+}
+
+// SortRuns sorts runs from the most to the least recently started
+func SortRuns(runs []Run) {
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+}
@@ -0,0 +1,268 @@
+package run_report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+// DefaultTopN the number of slowest targets included in a report by default
+const DefaultTopN = 5
+
+// Report a summary of the timing of a graph run
+type Report struct {
+	RunID    string        `json:"runId"`
+	WallTime time.Duration `json:"wallTime"`
+
+	// CriticalPath the chain of dependent targets with the longest combined wall time
+	// ordered from the first target executed to the last
+	CriticalPath         []ark.RunTarget `json:"criticalPath"`
+	CriticalPathDuration time.Duration   `json:"criticalPathDuration"`
+
+	// Slowest the targets with the longest wall time in descending order
+	Slowest []ark.RunTarget `json:"slowest"`
+
+	Targets    int `json:"targets"`
+	Cached     int `json:"cached"`
+	Skipped    int `json:"skipped"`
+	Executed   int `json:"executed"`
+	Failed     int `json:"failed"`
+	Unfinished int `json:"unfinished"`
+
+	// FailureAllowed the targets that failed without failing the run because their policy allows failure
+	FailureAllowed int `json:"failureAllowed"`
+
+	// Flaky the targets that succeeded after being retried
+	Flaky int `json:"flaky"`
+
+	// Blocked the targets that were not executed because a target failed
+	Blocked int `json:"blocked"`
+
+	// Failures every failed and blocked target ordered by key
+	Failures []ark.RunTarget `json:"failures"`
+
+	// Parallelism the sum of the wall time of every target divided by the wall time of the run
+	Parallelism    float64 `json:"parallelism"`
+	MaxConcurrency int     `json:"maxConcurrency"`
+}
+
+// CacheHitRate the fraction of finished targets whose artifacts were cached
+func (r Report) CacheHitRate() float64 {
+	finished := r.Cached + r.Executed + r.Failed
+	if finished == 0 {
+		return 0
+	}
+	return float64(r.Cached) / float64(finished)
+}
+
+// New computes a report for a run
+// The edges of the target graph are used to compute the critical path, edges to targets outside the run are ignored
+// If topN is less than 1 DefaultTopN is used
+func New(run ark.Run, edges []ark.GraphEdge, topN int) Report {
+	if topN < 1 {
+		topN = DefaultTopN
+	}
+
+	report := Report{
+		RunID:          run.ID,
+		WallTime:       run.Duration(),
+		Targets:        len(run.Targets),
+		MaxConcurrency: run.MaxConcurrency,
+	}
+
+	targets := make(map[string]ark.RunTarget, len(run.Targets))
+	var busy time.Duration
+	for _, target := range run.Targets {
+		targets[target.Key] = target
+		busy += target.Duration()
+
+		switch target.Status {
+		case ark.RunStatusCached:
+			report.Cached++
+		case ark.RunStatusSkipped:
+			report.Skipped++
+		case ark.RunStatusSuccess:
+			report.Executed++
+			if target.Flaky() {
+				report.Flaky++
+			}
+		case ark.RunStatusFailed:
+			report.Failed++
+			report.Failures = append(report.Failures, target)
+		case ark.RunStatusBlocked:
+			report.Blocked++
+			report.Failures = append(report.Failures, target)
+		case ark.RunStatusFailureAllowed:
+			report.FailureAllowed++
+		default:
+			report.Unfinished++
+		}
+	}
+
+	if report.WallTime > 0 {
+		report.Parallelism = float64(busy) / float64(report.WallTime)
+	}
+
+	sort.SliceStable(report.Failures, func(i, j int) bool {
+		return report.Failures[i].Key < report.Failures[j].Key
+	})
+
+	report.Slowest = append([]ark.RunTarget{}, run.Targets...)
+	sort.SliceStable(report.Slowest, func(i, j int) bool {
+		return report.Slowest[i].Duration() > report.Slowest[j].Duration()
+	})
+	if len(report.Slowest) > topN {
+		report.Slowest = report.Slowest[:topN]
+	}
+
+	report.CriticalPath, report.CriticalPathDuration = criticalPath(targets, edges)
+	return report
+}
+
+// criticalPath finds the chain of dependencies with the longest combined wall time
+func criticalPath(targets map[string]ark.RunTarget, edges []ark.GraphEdge) ([]ark.RunTarget, time.Duration) {
+	dependencies := make(map[string][]string)
+	for _, edge := range edges {
+		if _, ok := targets[edge.Src]; !ok {
+			continue
+		}
+		if _, ok := targets[edge.Dst]; !ok {
+			continue
+		}
+		dependencies[edge.Src] = append(dependencies[edge.Src], edge.Dst)
+	}
+
+	longest := make(map[string]time.Duration)
+	next := make(map[string]string)
+	visiting := make(map[string]bool)
+
+	var visit func(key string) time.Duration
+	visit = func(key string) time.Duration {
+		if d, ok := longest[key]; ok {
+			return d
+		}
+		// guards against cycles in edges that were not validated by a graph
+		if visiting[key] {
+			return 0
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+
+		var slowest time.Duration
+		for _, dependency := range dependencies[key] {
+			if d := visit(dependency); d > slowest || next[key] == "" {
+				slowest = d
+				next[key] = dependency
+			}
+		}
+
+		longest[key] = targets[key].Duration() + slowest
+		return longest[key]
+	}
+
+	// sorted keys keep the result deterministic when paths are equally long
+	keys := make([]string, 0, len(targets))
+	for key := range targets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var start string
+	var total time.Duration
+	for _, key := range keys {
+		if d := visit(key); start == "" || d > total {
+			start, total = key, d
+		}
+	}
+
+	var path []ark.RunTarget
+	for key := start; key != ""; key = next[key] {
+		path = append([]ark.RunTarget{targets[key]}, path...)
+	}
+	return path, total
+}
+
+// TraceEvent a complete event in the Chrome trace event format
+// https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type TraceEvent struct {
+	Name      string            `json:"name"`
+	Category  string            `json:"cat"`
+	Phase     string            `json:"ph"`
+	Timestamp int64             `json:"ts"`
+	Duration  int64             `json:"dur"`
+	PID       int               `json:"pid"`
+	TID       int               `json:"tid"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+// Trace a Chrome trace event document that can be loaded by chrome://tracing or https://ui.perfetto.dev
+type Trace struct {
+	TraceEvents     []TraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+// NewTrace converts the finished targets of a run to trace events
+// Overlapping targets are assigned to separate threads so the trace shows the parallelism of the run
+func NewTrace(run ark.Run) Trace {
+	trace := Trace{
+		TraceEvents:     []TraceEvent{},
+		DisplayTimeUnit: "ms",
+	}
+
+	var finished []ark.RunTarget
+	for _, target := range run.Targets {
+		if target.Duration() > 0 {
+			finished = append(finished, target)
+		}
+	}
+	sort.SliceStable(finished, func(i, j int) bool {
+		return finished[i].StartedAt.Before(finished[j].StartedAt)
+	})
+
+	// the time each thread becomes free
+	var threads []time.Time
+	for _, target := range finished {
+		tid := -1
+		for i, free := range threads {
+			if !free.After(target.StartedAt) {
+				tid = i
+				break
+			}
+		}
+		if tid == -1 {
+			tid = len(threads)
+			threads = append(threads, time.Time{})
+		}
+		threads[tid] = target.FinishedAt
+
+		args := map[string]string{
+			"hash":   target.Hash,
+			"type":   target.Type,
+			"status": string(target.Status),
+		}
+		if target.Error != "" {
+			args["error"] = target.Error
+		}
+
+		trace.TraceEvents = append(trace.TraceEvents, TraceEvent{
+			Name:      target.Key,
+			Category:  string(target.Status),
+			Phase:     "X",
+			Timestamp: target.StartedAt.Sub(run.StartedAt).Microseconds(),
+			Duration:  target.Duration().Microseconds(),
+			PID:       1,
+			TID:       tid + 1,
+			Args:      args,
+		})
+	}
+
+	return trace
+}
+
+// WriteTrace writes the Chrome trace of a run as json
+func WriteTrace(w io.Writer, run ark.Run) error {
+	return json.NewEncoder(w).Encode(NewTrace(run))
+}
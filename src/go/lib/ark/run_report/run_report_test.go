@@ -0,0 +1,81 @@
+package run_report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+func TestReport(t *testing.T) {
+	start := time.Now()
+	span := func(key string, status ark.RunStatus, from, to time.Duration) ark.RunTarget {
+		return ark.RunTarget{
+			Key:        key,
+			Status:     status,
+			StartedAt:  start.Add(from),
+			FinishedAt: start.Add(to),
+		}
+	}
+
+	// app depends on lib and assets, lib depends on base
+	run := ark.Run{
+		ID:             "run-1",
+		StartedAt:      start,
+		FinishedAt:     start.Add(10 * time.Second),
+		MaxConcurrency: 2,
+		Targets: ark.RunTargets{
+			span("base", ark.RunStatusCached, 0, time.Second),
+			span("assets", ark.RunStatusSuccess, 0, 6*time.Second),
+			span("lib", ark.RunStatusSuccess, time.Second, 5*time.Second),
+			span("app", ark.RunStatusSuccess, 6*time.Second, 10*time.Second),
+		},
+	}
+	edges := []ark.GraphEdge{
+		{Src: "app", Dst: "lib"},
+		{Src: "app", Dst: "assets"},
+		{Src: "lib", Dst: "base"},
+		{Src: "app", Dst: "not-in-run"},
+	}
+
+	t.Run("should summarize a run", func(t *testing.T) {
+		report := New(run, edges, 2)
+		require.Equal(t, 10*time.Second, report.WallTime)
+		require.Equal(t, 1, report.Cached)
+		require.Equal(t, 3, report.Executed)
+		require.Equal(t, 0.25, report.CacheHitRate())
+		require.Equal(t, 1.5, report.Parallelism)
+
+		require.Len(t, report.Slowest, 2)
+		require.Equal(t, "assets", report.Slowest[0].Key)
+
+		var path []string
+		for _, target := range report.CriticalPath {
+			path = append(path, target.Key)
+		}
+		require.Equal(t, []string{"assets", "app"}, path)
+		require.Equal(t, 10*time.Second, report.CriticalPathDuration)
+	})
+
+	t.Run("should export overlapping targets on separate threads", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		require.NoError(t, WriteTrace(buf, run))
+
+		var trace Trace
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &trace))
+		require.Len(t, trace.TraceEvents, 4)
+
+		threads := make(map[string]int)
+		for _, event := range trace.TraceEvents {
+			require.Equal(t, "X", event.Phase)
+			threads[event.Name] = event.TID
+		}
+		require.NotEqual(t, threads["base"], threads["assets"])
+		require.Equal(t, threads["base"], threads["lib"])
+		require.Equal(t, int64(6*time.Second/time.Microsecond), trace.TraceEvents[3].Timestamp)
+	})
+}
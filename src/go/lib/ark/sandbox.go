@@ -0,0 +1,261 @@
+package ark
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SandboxDefaultPath the PATH of a sandboxed action that does not declare one in its environment
+const SandboxDefaultPath = "/usr/local/bin:/usr/bin:/bin:/usr/sbin:/sbin"
+
+// DependencyOutputsUser an action that is given the absolute paths of the outputs declared by the targets it depends on
+// The graph walker restores the outputs of every dependency before the action is executed
+type DependencyOutputsUser interface {
+	UseDependencyOutputs(paths []string)
+}
+
+// isolationTools the executables that hide the realm from a sandboxed action by platform in order of preference
+// bwrap and unshare mount the execroot over the realm in a private mount namespace, sandbox-exec denies access to the realm
+var isolationTools = map[string][]string{
+	"linux":  {"bwrap", "unshare"},
+	"darwin": {"sandbox-exec"},
+}
+
+// Sandbox a temporary execroot that mirrors the layout of a realm but only contains the inputs staged into it
+// Actions executed in a sandbox are isolated from the workspace, reading an undeclared file of the realm fails
+// through relative and absolute paths alike. Their environment only contains the variables they declare,
+// so cache hits don't depend on the host they ran on
+type Sandbox struct {
+	Realm string
+	Root  string
+}
+
+// NewSandbox creates an empty sandbox for a realm, the caller is responsible for removing it
+func NewSandbox(realm string) (*Sandbox, error) {
+	root, err := os.MkdirTemp("", "ark-sandbox-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create sandbox")
+	}
+
+	sandbox := &Sandbox{Realm: realm, Root: root}
+	if _, err = sandbox.Path(root); err == nil {
+		_ = sandbox.Remove()
+		return nil, errors.Errorf("the sandbox %s can't be created inside of the realm %s it isolates the action from", root, realm)
+	}
+	for _, dir := range []string{sandbox.Execroot(), sandbox.Home(), sandbox.TempDir()} {
+		if err = os.MkdirAll(dir, 0700); err != nil {
+			_ = sandbox.Remove()
+			return nil, err
+		}
+	}
+	return sandbox, nil
+}
+
+// Execroot returns the directory that mirrors the realm
+func (s Sandbox) Execroot() string {
+	return filepath.Join(s.Root, "execroot")
+}
+
+// Home returns the HOME directory of the sandboxed action
+func (s Sandbox) Home() string {
+	return filepath.Join(s.Root, "home")
+}
+
+// TempDir returns the TMPDIR of the sandboxed action
+func (s Sandbox) TempDir() string {
+	return filepath.Join(s.Root, "tmp")
+}
+
+// Path maps an absolute path of the realm to its location in the execroot
+func (s Sandbox) Path(path string) (string, error) {
+	rel, err := filepath.Rel(s.Realm, path)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("%s is outside of the realm %s and can't be sandboxed", path, s.Realm)
+	}
+	return filepath.Join(s.Execroot(), rel), nil
+}
+
+// Stage copies files or directories of the realm into the execroot
+// Inputs are copied rather than linked so the action can't modify the workspace through them
+func (s Sandbox) Stage(paths []string, ignoreNotExist bool) error {
+	for _, path := range paths {
+		dest, err := s.Path(path)
+		if err != nil {
+			return err
+		}
+
+		if _, err = os.Stat(path); os.IsNotExist(err) {
+			if ignoreNotExist {
+				continue
+			}
+			return errors.Errorf("declared input %s does not exist", path)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = copyTree(path, dest); err != nil {
+			return errors.Wrapf(err, "failed to stage %s", path)
+		}
+	}
+	return nil
+}
+
+// Collect replaces files or directories of the realm with the copies the action produced in the execroot
+func (s Sandbox) Collect(paths []string) error {
+	for _, path := range paths {
+		src, err := s.Path(path)
+		if err != nil {
+			return err
+		}
+
+		if _, err = os.Stat(src); os.IsNotExist(err) {
+			return errors.Errorf("declared output %s was not produced in the sandbox", path)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = os.RemoveAll(path); err != nil {
+			return err
+		}
+		if err = copyTree(src, path); err != nil {
+			return errors.Wrapf(err, "failed to collect %s", path)
+		}
+	}
+	return nil
+}
+
+// Environment returns the scrubbed environment of the sandboxed action
+// Only the declared variables are passed through, PATH defaults to SandboxDefaultPath unless it is declared
+func (s Sandbox) Environment(declared map[string]string) map[string]string {
+	env := map[string]string{
+		"PATH":   SandboxDefaultPath,
+		"HOME":   s.Home(),
+		"TMPDIR": s.TempDir(),
+	}
+	for key, value := range declared {
+		env[key] = value
+	}
+	return env
+}
+
+// Command creates a command that runs in dir with the scrubbed environment of the sandbox
+// dir is a path of the realm, the command is resolved and its arguments are expanded against the sandbox environment only
+// The command is wrapped by the isolation tool of the host, an error is returned if the host has none
+func (s Sandbox) Command(ctx context.Context, command []string, dir string, declared map[string]string) (*exec.Cmd, error) {
+	if len(command) == 0 {
+		return nil, errors.New("a sandboxed command cannot be empty")
+	}
+
+	tool, err := SandboxIsolation()
+	if err != nil {
+		return nil, err
+	}
+
+	workingDir, err := s.Path(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(workingDir, 0755); err != nil {
+		return nil, err
+	}
+
+	// the execroot is mounted over the realm so the action sees its working directory at the path of the realm
+	env := s.Environment(declared)
+	env["PWD"] = dir
+	if !mountsExecroot(tool) {
+		env["PWD"] = workingDir
+	}
+
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = os.Expand(arg, func(key string) string {
+			return env[key]
+		})
+	}
+
+	name, err := lookPath(args[0], env["PATH"])
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := s.isolate(ctx, tool, env["PWD"], append([]string{name}, args[1:]...))
+	cmd.Dir = workingDir
+	cmd.Env = []string{}
+	for key, value := range env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return cmd, nil
+}
+
+// isolate wraps a command with the isolation tool so it runs in dir without access to the files of the realm
+func (s Sandbox) isolate(ctx context.Context, tool, dir string, command []string) *exec.Cmd {
+	var args []string
+	switch filepath.Base(tool) {
+	case "bwrap":
+		args = []string{"--dev-bind", "/", "/", "--bind", s.Execroot(), s.Realm, "--chdir", dir, "--die-with-parent", "--"}
+	case "unshare":
+		// the action runs as root of a user namespace so it is allowed to mount, files it creates are owned by the caller
+		script := `mount --bind "$1" "$2" && cd "$3" && shift 3 && exec "$@"`
+		args = []string{"--user", "--map-root-user", "--mount", "--", "/bin/sh", "-c", script, "sh", s.Execroot(), s.Realm, dir}
+	default:
+		profile := fmt.Sprintf("(version 1)(allow default)(deny file-read* file-write* (subpath %q))", s.Realm)
+		args = []string{"-p", profile}
+	}
+	return exec.CommandContext(ctx, tool, append(args, command...)...)
+}
+
+// SandboxIsolation returns the tool that isolates sandboxed actions from the realm on this host
+// Sandboxed actions can't run on a host without one
+func SandboxIsolation() (string, error) {
+	for _, tool := range isolationTools[runtime.GOOS] {
+		if path, err := lookPath(tool, SandboxDefaultPath); err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.Errorf(
+		"sandboxed actions require one of %s on %s to hide undeclared inputs",
+		strings.Join(isolationTools[runtime.GOOS], ", "), runtime.GOOS,
+	)
+}
+
+// mountsExecroot returns true if the tool mounts the execroot at the path of the realm
+func mountsExecroot(tool string) bool {
+	switch filepath.Base(tool) {
+	case "bwrap", "unshare":
+		return true
+	default:
+		return false
+	}
+}
+
+// Remove deletes the sandbox and everything the action left in it
+func (s Sandbox) Remove() error {
+	return os.RemoveAll(s.Root)
+}
+
+// lookPath resolves an executable against the PATH of the sandbox rather than the PATH of the host
+func lookPath(file, path string) (string, error) {
+	if strings.Contains(file, string(filepath.Separator)) {
+		return file, nil
+	}
+	for _, dir := range filepath.SplitList(path) {
+		candidate := filepath.Join(dir, file)
+		info, err := os.Stat(candidate)
+		if err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return candidate, nil
+		}
+	}
+	return "", errors.Errorf("%s was not found in the sandbox PATH %s", file, path)
+}
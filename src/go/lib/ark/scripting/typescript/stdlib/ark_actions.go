@@ -5,7 +5,11 @@ import (
 	"github.com/myfintech/ark/src/go/lib/ark"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/docker_image"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/group"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/http_archive"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/jsonnet"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/kube_exec"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/kube_secret"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/local_exec"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/local_file"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/nix"
 	"github.com/myfintech/ark/src/go/lib/ark/targets/probe"
@@ -22,10 +26,14 @@ func NewArkActionLibrary(opts Options) typescript.Module {
 		"deploy":           NewAddTargetFunc("deploy", opts),
 		"syncKV":           NewAddTargetFunc(sync_kv.Type, opts),
 		"kubeExec":         NewAddTargetFunc(kube_exec.Type, opts),
+		"kubeSecret":       NewAddTargetFunc(kube_secret.Type, opts),
 		"group":            NewAddTargetFunc(group.Type, opts),
+		"httpArchive":      NewAddTargetFunc(http_archive.Type, opts),
+		"jsonnet":          NewAddTargetFunc(jsonnet.Type, opts),
 		"probe":            NewAddTargetFunc(probe.Type, opts),
 		"test":             NewAddTargetFunc(test.Type, opts),
 		"localFile":        NewAddTargetFunc(local_file.Type, opts),
+		"localExec":        NewAddTargetFunc(local_exec.Type, opts),
 		"nix":              NewAddTargetFunc(nix.Type, opts),
 		"connectTargets":   NewConnectTargetFunc(opts),
 	}
@@ -18,12 +18,13 @@ type Store interface {
 	GetGraphEdges() ([]GraphEdge, error)
 	Open(connection string) error
 	Migrate() error
+	RunStore
 }
 
 // GraphEdge represents the src key and dst key between two targets in a graph
 type GraphEdge struct {
-	Src string `json:"src"`
-	Dst string `json:"dst"`
+	Src string `json:"src" gorm:"primaryKey"`
+	Dst string `json:"dst" gorm:"primaryKey"`
 }
 
 // Validate ensure graph edge is valid
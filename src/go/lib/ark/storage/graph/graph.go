@@ -8,24 +8,29 @@ import (
 
 // FromStore accepts an ark store and produces a DAG from targets and edges
 func FromStore(store ark.Store) (*dag.AcyclicGraph, error) {
-	graph := new(dag.AcyclicGraph)
-	targetsCache := make(map[string]ark.RawTarget)
-
 	targets, err := store.GetTargets()
 	if err != nil {
-		return graph, err
+		return new(dag.AcyclicGraph), err
+	}
+
+	edges, err := store.GetGraphEdges()
+	if err != nil {
+		return new(dag.AcyclicGraph), err
 	}
 
+	return FromTargetsAndEdges(targets, edges)
+}
+
+// FromTargetsAndEdges produces a DAG from a list of targets and the edges between them
+func FromTargetsAndEdges(targets []ark.RawTarget, edges []ark.GraphEdge) (*dag.AcyclicGraph, error) {
+	graph := new(dag.AcyclicGraph)
+	targetsCache := make(map[string]ark.RawTarget)
+
 	for _, target := range targets {
 		graph.Add(target)
 		targetsCache[target.Key()] = target
 	}
 
-	edges, err := store.GetGraphEdges()
-	if err != nil {
-		return graph, err
-	}
-
 	for _, edge := range edges {
 		src, exists := targetsCache[edge.Src]
 		if !exists {
@@ -16,6 +16,7 @@ import (
 type Store struct {
 	targets    sync.Map
 	graphEdges sync.Map
+	runs       sync.Map
 }
 
 // GetTargets returns a list of []ark.RawTarget from the memory state
@@ -82,6 +83,46 @@ func (s *Store) GetGraph() (*dag.AcyclicGraph, error) {
 	return graph.FromStore(s)
 }
 
+// MaxRuns the number of runs kept in memory state, the least recently started runs are removed first
+const MaxRuns = 200
+
+// SaveRun creates or replaces a run in memory state
+func (s *Store) SaveRun(run ark.Run) error {
+	if run.ID == "" {
+		return errors.New("a run must have an id")
+	}
+	s.runs.Store(run.ID, run)
+
+	runs, err := s.GetRuns()
+	if err != nil || len(runs) <= MaxRuns {
+		return err
+	}
+	for _, old := range runs[MaxRuns:] {
+		s.runs.Delete(old.ID)
+	}
+	return nil
+}
+
+// GetRuns returns the list of runs in memory sorted from the most recently started
+func (s *Store) GetRuns() (runs []ark.Run, err error) {
+	s.runs.Range(func(key, r interface{}) bool {
+		runs = append(runs, r.(ark.Run))
+		return true
+	})
+	ark.SortRuns(runs)
+	return
+}
+
+// GetRunByID returns a run by its id with an error if it doesn't exist
+func (s *Store) GetRunByID(id string) (run ark.Run, err error) {
+	if v, ok := s.runs.Load(id); ok {
+		return v.(ark.Run), nil
+	}
+
+	err = errors.Errorf("failed to locate run by id %s", id)
+	return
+}
+
 // Open is a noop in the memory Store
 func (s *Store) Open(_ string) error {
 	return nil
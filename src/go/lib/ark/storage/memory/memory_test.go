@@ -0,0 +1,26 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+func TestStore_maxRuns(t *testing.T) {
+	store := new(Store)
+	start := time.Now()
+	for i := 0; i <= MaxRuns; i++ {
+		require.NoError(t, store.SaveRun(ark.Run{ID: fmt.Sprintf("run-%d", i), StartedAt: start.Add(time.Duration(i) * time.Second)}))
+	}
+
+	runs, err := store.GetRuns()
+	require.NoError(t, err)
+	require.Len(t, runs, MaxRuns)
+
+	_, err = store.GetRunByID("run-0")
+	require.Error(t, err, "the least recently started run is removed")
+}
@@ -1,30 +1,66 @@
 package sqlite
 
 import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+
 	"github.com/myfintech/ark/src/go/lib/ark"
 	"github.com/myfintech/ark/src/go/lib/ark/derivation"
 	"github.com/myfintech/ark/src/go/lib/ark/storage/graph"
 	"github.com/myfintech/ark/src/go/lib/dag"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
+	"github.com/myfintech/ark/src/go/lib/xdgbase"
 )
 
-// DefaultStore is the default sqlite storage interface for Ark
+// DefaultFileName is the name of the sqlite database file created in the ark data directory
+const DefaultFileName = "store.db"
+
+// DefaultConnection returns the path to the default sqlite database file in the ark data directory
+// The location respects the XDG Base Directory Specification ($ARK_DATA_HOME, $XDG_DATA_HOME, $HOME/.local/share/ark)
+func DefaultConnection() (string, error) {
+	dataDir, err := xdgbase.Dir("ark", xdgbase.DataSuffix)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, DefaultFileName), nil
+}
 
 // Store implements the Store interface
 type Store struct {
 	DB *gorm.DB `gorm:"-"`
 }
 
-// GetTargetByKey not implemented
-// FIXME: implement GetTargetByKey
-func (s *Store) GetTargetByKey(_ string) (ark.RawTarget, error) {
-	panic("implement me")
+// GetTargetByKey returns a target by its key with an error if it doesn't exist
+func (s *Store) GetTargetByKey(key string) (target ark.RawTarget, err error) {
+	result := s.DB.Where("id = ?", key).Limit(1).Find(&target)
+	if result.Error != nil {
+		err = result.Error
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		err = errors.Errorf("failed to locate target by key %s", key)
+		return
+	}
+	return
 }
 
-// Open opens the db file that was passed
+// Open opens the db file that was passed, creating its parent directory if necessary
 func (s *Store) Open(connection string) error {
-	db, err := gorm.Open(sqlite.Open(connection), &gorm.Config{})
+	if connection != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(connection), 0755); err != nil {
+			return err
+		}
+	}
+
+	db, err := gorm.Open(sqlite.Open(connection), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
 	if err != nil {
 		return err
 	}
@@ -32,11 +68,12 @@ func (s *Store) Open(connection string) error {
 	return nil
 }
 
-// Migrate ...
+// Migrate creates or updates the tables required by the store
 func (s *Store) Migrate() error {
 	return s.DB.AutoMigrate(
 		&ark.RawTarget{},
 		&ark.GraphEdge{},
+		&ark.Run{},
 	)
 }
 
@@ -49,24 +86,38 @@ func (s *Store) GetTargets() ([]ark.RawTarget, error) {
 	return targets, result.Error
 }
 
-// AddTarget adds a new target to the database
+// AddTarget validates and upserts a target, replacing the edges to its ancestors
 func (s *Store) AddTarget(target ark.RawTarget) (artifact ark.RawArtifact, err error) {
+	if err = target.Validate(); err != nil {
+		return
+	}
+
 	artifact, err = derivation.RawArtifactFromRawTarget(target)
 	if err != nil {
 		return
 	}
 
 	err = s.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.FirstOrCreate(&target).Error; err != nil {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&target).Error; err != nil {
 			return err
 		}
 
-		return tx.Updates(&target).Error
-	})
+		// the ancestors of a target may change between evaluations of a build file
+		// stale edges must be removed so they don't survive a server restart
+		if err := tx.Where("src = ?", target.Key()).Delete(&ark.GraphEdge{}).Error; err != nil {
+			return err
+		}
 
-	if err != nil {
-		return
-	}
+		for _, ancestor := range target.DependsOn {
+			if err := connectTargets(tx, ark.GraphEdge{
+				Src: target.Key(),
+				Dst: ancestor.Key,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	return
 }
 
@@ -74,12 +125,19 @@ func (s *Store) AddTarget(target ark.RawTarget) (artifact ark.RawArtifact, err e
 // The data in this table is used to build a graph
 func (s *Store) ConnectTargets(edge ark.GraphEdge) error {
 	return s.DB.Transaction(func(tx *gorm.DB) error {
-		return tx.FirstOrCreate(&edge).Error
+		return connectTargets(tx, edge)
 	})
 }
 
+func connectTargets(tx *gorm.DB, edge ark.GraphEdge) error {
+	if err := edge.Validate(); err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&edge).Error
+}
+
 // GetGraphEdges find all the edges to build a graph
-func (s Store) GetGraphEdges() ([]ark.GraphEdge, error) {
+func (s *Store) GetGraphEdges() ([]ark.GraphEdge, error) {
 	var edges []ark.GraphEdge
 
 	result := s.DB.Find(&edges)
@@ -91,3 +149,35 @@ func (s Store) GetGraphEdges() ([]ark.GraphEdge, error) {
 func (s *Store) GetGraph() (*dag.AcyclicGraph, error) {
 	return graph.FromStore(s)
 }
+
+// SaveRun creates or replaces a run
+func (s *Store) SaveRun(run ark.Run) error {
+	if run.ID == "" {
+		return errors.New("a run must have an id")
+	}
+	return s.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&run).Error
+}
+
+// GetRuns returns a list of runs sorted from the most recently started
+func (s *Store) GetRuns() ([]ark.Run, error) {
+	var runs []ark.Run
+
+	result := s.DB.Order("started_at desc").Find(&runs)
+
+	return runs, result.Error
+}
+
+// GetRunByID returns a run by its id with an error if it doesn't exist
+func (s *Store) GetRunByID(id string) (run ark.Run, err error) {
+	result := s.DB.Where("id = ?", id).Limit(1).Find(&run)
+	if result.Error != nil {
+		err = result.Error
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		err = errors.Errorf("failed to locate run by id %s", id)
+		return
+	}
+	return
+}
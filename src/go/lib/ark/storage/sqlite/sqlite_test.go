@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/targets/group"
+)
+
+func TestStore(t *testing.T) {
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	connection := filepath.Join(t.TempDir(), DefaultFileName)
+
+	store := new(Store)
+	require.NoError(t, store.Open(connection))
+	require.NoError(t, store.Migrate())
+
+	child := ark.RawTarget{
+		Name:  "child",
+		Type:  group.Type,
+		File:  filepath.Join(cwd, "build.ts"),
+		Realm: cwd,
+	}
+
+	childArtifact, err := store.AddTarget(child)
+	require.NoError(t, err)
+
+	parent := ark.RawTarget{
+		Name:  "parent",
+		Type:  group.Type,
+		File:  filepath.Join(cwd, "build.ts"),
+		Realm: cwd,
+		DependsOn: ark.Ancestors{
+			{Key: child.Key(), Hash: childArtifact.Hash},
+		},
+	}
+
+	_, err = store.AddTarget(parent)
+	require.NoError(t, err)
+
+	t.Run("should be able to get a target by its key", func(t *testing.T) {
+		target, err := store.GetTargetByKey(parent.Key())
+		require.NoError(t, err)
+		require.Equal(t, parent.Key(), target.Key())
+		require.Len(t, target.DependsOn, 1)
+
+		_, err = store.GetTargetByKey("does/not/exist:target")
+		require.Error(t, err)
+	})
+
+	t.Run("should upsert targets and their edges", func(t *testing.T) {
+		_, err := store.AddTarget(parent)
+		require.NoError(t, err)
+
+		targets, err := store.GetTargets()
+		require.NoError(t, err)
+		require.Len(t, targets, 2)
+
+		edges, err := store.GetGraphEdges()
+		require.NoError(t, err)
+		require.Equal(t, []ark.GraphEdge{{Src: parent.Key(), Dst: child.Key()}}, edges)
+	})
+
+	t.Run("should survive reopening the database", func(t *testing.T) {
+		reopened := new(Store)
+		require.NoError(t, reopened.Open(connection))
+		require.NoError(t, reopened.Migrate())
+
+		graph, err := reopened.GetGraph()
+		require.NoError(t, err)
+		require.Len(t, graph.Vertices(), 2)
+		require.Len(t, graph.Edges(), 1)
+	})
+
+	t.Run("should persist and update runs", func(t *testing.T) {
+		started := time.Now().UTC().Truncate(time.Second)
+		run := ark.Run{
+			ID:         "run-1",
+			TargetKeys: []string{parent.Key()},
+			Status:     ark.RunStatusRunning,
+			StartedAt:  started,
+		}
+		run.Target(child.Key()).Status = ark.RunStatusCached
+		require.NoError(t, store.SaveRun(run))
+
+		run.Status = ark.RunStatusSuccess
+		run.FinishedAt = started.Add(time.Second)
+		require.NoError(t, store.SaveRun(run))
+		require.NoError(t, store.SaveRun(ark.Run{ID: "run-2", StartedAt: started.Add(time.Minute)}))
+
+		saved, err := store.GetRunByID("run-1")
+		require.NoError(t, err)
+		require.Equal(t, ark.RunStatusSuccess, saved.Status)
+		require.Equal(t, time.Second, saved.Duration())
+		require.Len(t, saved.Targets, 1)
+		require.True(t, saved.Targets[0].Cached())
+
+		runs, err := store.GetRuns()
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		require.Equal(t, "run-2", runs[0].ID)
+
+		_, err = store.GetRunByID("does-not-exist")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,60 @@
+package embedded_broker
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/events"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/protocols/nats"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/topics"
+	"github.com/myfintech/ark/src/go/lib/xdgbase"
+)
+
+const (
+	// NATS the embedded NATS server using core pub/sub, events published while nobody is subscribed are lost
+	NATS = "nats-embedded"
+
+	// NATSJetStream the embedded NATS server retaining the graph events of every run in a JetStream stream
+	NATSJetStream = "nats-jetstream"
+)
+
+// RetentionPeriod the duration the JetStream broker retains the graph events of a run after it finished
+const RetentionPeriod = 24 * time.Hour
+
+// ServerOptions returns the options of the embedded NATS server for the broker type
+// The JetStream streams are stored in $ARK_DATA_HOME/jetstream
+func ServerOptions(brokerType string) (server.Options, error) {
+	switch brokerType {
+	case NATS:
+		return nats.DefaultServerOptions, nil
+	case NATSJetStream:
+		dataDir, err := xdgbase.Dir("ark", xdgbase.DataSuffix)
+		if err != nil {
+			return server.Options{}, err
+		}
+		return nats.JetStreamServerOptions(filepath.Join(dataDir, "jetstream")), nil
+	default:
+		return server.Options{}, errors.Errorf("requested broker type %s is not supported", brokerType)
+	}
+}
+
+// NewBroker creates the broker of the broker type connected to the embedded NATS server
+func NewBroker(brokerType string, nc *natsgo.Conn) (cqrs.Broker, error) {
+	switch brokerType {
+	case NATS:
+		return nats.NewBroker(nc), nil
+	case NATSJetStream:
+		return nats.NewJetStreamBroker(nc, nats.JetStreamOptions{
+			Topics:     []cqrs.RouteKey{topics.GraphRunnerEvents, topics.GraphWalkerEvents},
+			MaxAge:     RetentionPeriod,
+			FinalTypes: []cqrs.RouteKey{events.GraphRunnerSuccess, events.GraphRunnerFailed},
+		})
+	default:
+		return nil, errors.Errorf("requested broker type %s is not supported", brokerType)
+	}
+}
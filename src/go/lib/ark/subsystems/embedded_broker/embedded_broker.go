@@ -26,12 +26,16 @@ func NewSubsystem(brokerType, _ string, logger logz.FieldLogger, natsd *server.S
 		Factory: func(wg *sync.WaitGroup, ctx context.Context) func() error {
 			return func() error {
 
-				if brokerType != "nats-embedded" {
+				if brokerType != NATS && brokerType != NATSJetStream {
 					return errors.Errorf("requested broker type %s is not supported", brokerType)
 				}
 
 				if natsd == nil {
-					s, err := nats.RunServer(nil)
+					opts, err := ServerOptions(brokerType)
+					if err != nil {
+						return err
+					}
+					s, err := nats.RunServer(&opts)
 					if err != nil {
 						return err
 					}
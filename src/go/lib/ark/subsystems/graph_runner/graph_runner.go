@@ -3,7 +3,9 @@ package graph_runner
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/myfintech/ark/src/go/lib/kube"
 
@@ -27,8 +29,10 @@ import (
 	"github.com/myfintech/ark/src/go/lib/ark"
 	"github.com/myfintech/ark/src/go/lib/ark/cqrs/topics"
 	"github.com/myfintech/ark/src/go/lib/ark/graph"
+	"github.com/myfintech/ark/src/go/lib/ark/local_cache"
 	"github.com/myfintech/ark/src/go/lib/ark/shared_clients"
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems"
+	"github.com/myfintech/ark/src/go/lib/ark/workspace"
 	"github.com/myfintech/ark/src/go/lib/logz"
 )
 
@@ -64,6 +68,59 @@ func newOnMessageErrFunc(broker cqrs.Broker) cqrs.OnMessageErrorFunc {
 type runnerState struct {
 	mutex      sync.Mutex
 	executions map[string]context.CancelFunc
+
+	// cacheMutex guards the number of executions using the local cache
+	// it is only held while a single artifact is evicted so a collection never blocks new executions for long
+	cacheMutex sync.Mutex
+	executing  int
+	collecting bool
+	collected  time.Time
+}
+
+// collectionInterval the minimum duration between two collections of the local cache
+var collectionInterval = time.Minute
+
+// beginExecution records a graph execution that uses the local cache
+// it only blocks while an artifact is being evicted
+func (r *runnerState) beginExecution() {
+	r.cacheMutex.Lock()
+	defer r.cacheMutex.Unlock()
+	r.executing++
+}
+
+// endExecution records the end of a graph execution and runs collect if no other execution is using the local cache
+// collect runs without holding the lock, at most once per collectionInterval and never concurrently with another collection
+func (r *runnerState) endExecution(collect func()) {
+	r.cacheMutex.Lock()
+	r.executing--
+	start := r.executing == 0 && !r.collecting && time.Since(r.collected) >= collectionInterval
+	if start {
+		r.collecting = true
+		r.collected = time.Now()
+	}
+	r.cacheMutex.Unlock()
+
+	if !start {
+		return
+	}
+
+	defer func() {
+		r.cacheMutex.Lock()
+		defer r.cacheMutex.Unlock()
+		r.collecting = false
+	}()
+	collect()
+}
+
+// whileIdle calls fn if no graph execution is using the local cache and returns false otherwise
+// executions can't begin until fn returns
+func (r *runnerState) whileIdle(fn func() error) (bool, error) {
+	r.cacheMutex.Lock()
+	defer r.cacheMutex.Unlock()
+	if r.executing > 0 {
+		return false, nil
+	}
+	return true, fn()
 }
 
 func (r *runnerState) store(id string, ctx context.Context) context.Context {
@@ -89,6 +146,7 @@ func newRunnerState() *runnerState {
 
 func newOnMessageFunc(store ark.Store, sharedClients shared_clients.Container, broker cqrs.Broker, logger logz.FieldLogger) cqrs.OnMessageFunc {
 	state := newRunnerState()
+	sharedExecutions := graph.NewSharedExecutions()
 	return func(ctx context.Context, msg cqrs.Envelope) error {
 		if msg.Error != nil {
 			return errors.Wrap(msg.Error, "failed to deserialize incoming envelope")
@@ -107,6 +165,15 @@ func newOnMessageFunc(store ark.Store, sharedClients shared_clients.Container, b
 			return errors.Wrap(err, "failed to unmarshal the incoming command")
 		}
 
+		skipMode, err := graph.ParseSkipMode(cmd.SkipMode)
+		if err != nil {
+			return err
+		}
+
+		if len(cmd.TargetKeys) == 0 && len(cmd.Labels) == 0 {
+			return errors.New("the command did not specify any target keys or labels")
+		}
+
 		logger.Debugf("saving context for future cancellation %s", msg.Subject())
 		ctx = state.store(msg.Subject(), ctx)
 		defer state.stop(msg.Subject())
@@ -115,6 +182,7 @@ func newOnMessageFunc(store ark.Store, sharedClients shared_clients.Container, b
 			sources.GraphRunner,
 			events.GraphRunnerStartedType,
 			cqrs.WithSubject(cqrs.RouteKey(msg.Subject())),
+			cqrs.WithData(cqrs.ApplicationJSON, cmd),
 		)); err != nil {
 			return err
 		}
@@ -127,7 +195,7 @@ func newOnMessageFunc(store ark.Store, sharedClients shared_clients.Container, b
 			logz.WithFields(logz.Fields{
 				"system":         topics.GraphWalker.String(),
 				"subscriptionID": msg.Subject(),
-				"target_key":     cmd.TargetKeys[0],
+				"target_keys":    strings.Join(cmd.TargetKeys, ","),
 			}),
 		)
 
@@ -155,23 +223,34 @@ func newOnMessageFunc(store ark.Store, sharedClients shared_clients.Container, b
 		sharedClients.Docker.OutputWriter = ctxLogger
 
 		ctxLogger.Debug("starting graph execution")
+		state.beginExecution()
 		err = graph.Execute(graph.ExecuteOptions{
 			Ctx:                         ctx,
 			Store:                       store,
 			SharedClients:               &sharedClients,
-			RootTargetKey:               cmd.TargetKeys[0],
+			RootTargetKeys:              cmd.TargetKeys,
+			Labels:                      ark.NewLabelSelector(cmd.Labels, cmd.ExcludeLabels),
 			Broker:                      broker,
 			SubscriptionID:              msg.Subject(),
 			ForceExecution:              cmd.ForceBuild,
+			KeepGoing:                   cmd.KeepGoing,
 			PushArtifactsAfterExecution: cmd.PushAfterBuild,
 			SkipFilters:                 cmd.SkipFilters,
+			SkipMode:                    skipMode,
 			K8sNamespace:                cmd.K8sNamespace,
 			K8sContext:                  cmd.K8sContext,
 			Logger:                      ctxLogger,
 			MaxConcurrency:              cmd.MaxConcurrency,
+			ResourcePools:               sharedClients.WorkspaceConfig.Scheduler.Pools,
+			SharedExecutions:            sharedExecutions,
 		})
 		ctxLogger.Debug("graph execution completed")
 
+		// the cache is only collected once every concurrent run finished using it
+		state.endExecution(func() {
+			collectLocalCacheGarbage(sharedClients.WorkspaceConfig, state, ctxLogger)
+		})
+
 		if err != nil {
 			return errors.Wrapf(err, "could not execute the graph for the given keys %s", cmd.TargetKeys)
 		}
@@ -183,3 +262,51 @@ func newOnMessageFunc(store ark.Store, sharedClients shared_clients.Container, b
 		))
 	}
 }
+
+// collectLocalCacheGarbage enforces the local artifact cache limits after a graph execution
+// the cache is walked without blocking new executions, artifacts are only evicted while no execution is using the cache
+// the collection stops as soon as an execution begins and continues after the next one
+// failures are logged because they should never fail the execution itself
+func collectLocalCacheGarbage(config workspace.Config, state *runnerState, logger logz.FieldLogger) {
+	manager, err := local_cache.NewManager(config)
+	if err != nil {
+		logger.Warnf("failed to initialize the local cache manager %v", err)
+		return
+	}
+
+	if !manager.Limited() {
+		return
+	}
+
+	planned, err := manager.GC(true)
+	if err != nil {
+		logger.Warnf("failed to collect local cache garbage %v", err)
+		return
+	}
+
+	var evicted int
+	var freed int64
+	for _, entry := range planned.Evicted {
+		var removed bool
+		idle, err := state.whileIdle(func() (err error) {
+			removed, err = manager.Evict(entry)
+			return err
+		})
+		if err != nil {
+			logger.Warnf("failed to collect local cache garbage %v", err)
+			break
+		}
+		if !idle {
+			logger.Debugf("stopped collecting the local cache because a graph execution began")
+			break
+		}
+		if removed {
+			evicted++
+			freed += entry.Size
+		}
+	}
+
+	if evicted > 0 {
+		logger.Debugf("evicted %d artifacts (%d bytes) from the local cache", evicted, freed)
+	}
+}
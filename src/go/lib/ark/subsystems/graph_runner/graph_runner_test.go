@@ -69,3 +69,55 @@ func TestNewSubsystem(t *testing.T) {
 	require.NoError(t, eg.Wait())
 
 }
+
+func TestRunnerState_endExecution(t *testing.T) {
+	state := newRunnerState()
+	collected := 0
+	collect := func() { collected++ }
+
+	state.beginExecution()
+	state.beginExecution()
+
+	state.endExecution(collect)
+	require.Equal(t, 0, collected, "the cache must not be collected while another run is executing")
+
+	state.endExecution(collect)
+	require.Equal(t, 1, collected)
+}
+
+func TestRunnerState_collection(t *testing.T) {
+	t.Run("should collect at most once per interval", func(t *testing.T) {
+		state := newRunnerState()
+		collected := 0
+		collect := func() { collected++ }
+
+		state.beginExecution()
+		state.endExecution(collect)
+		state.beginExecution()
+		state.endExecution(collect)
+		require.Equal(t, 1, collected)
+	})
+
+	t.Run("should not block executions while collecting", func(t *testing.T) {
+		state := newRunnerState()
+		state.beginExecution()
+		state.endExecution(func() {
+			state.beginExecution()
+
+			idle, err := state.whileIdle(func() error {
+				t.Fatal("nothing may be evicted while an execution uses the cache")
+				return nil
+			})
+			require.NoError(t, err)
+			require.False(t, idle)
+
+			state.endExecution(func() {
+				t.Fatal("collections must not run concurrently")
+			})
+		})
+
+		idle, err := state.whileIdle(func() error { return nil })
+		require.NoError(t, err)
+		require.True(t, idle)
+	})
+}
@@ -1,9 +1,13 @@
 package http_server
 
 import (
+	"context"
 	"io"
 
+	"github.com/pkg/errors"
+
 	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs"
 	"github.com/myfintech/ark/src/go/lib/ark/cqrs/messages"
 	"github.com/myfintech/ark/src/go/lib/dag"
 )
@@ -12,10 +16,17 @@ import (
 type Client interface {
 	AddTarget(target ark.RawTarget) (ark.RawArtifact, error)
 	GetTargets() ([]ark.RawTarget, error)
+	GetTargetsByLabels(labels ark.LabelSelector) ([]ark.RawTarget, error)
 	ConnectTargets(edge ark.GraphEdge) (ark.GraphEdge, error)
 	GetGraph() (*dag.AcyclicGraph, error)
 	GetGraphEdges() ([]ark.GraphEdge, error)
 	Run(cmd messages.GraphRunnerExecuteCommand) (messages.GraphRunnerExecuteCommandResponse, error)
 	GetServerLogs() (io.Reader, error)
 	GetLogsByKey(logKey string) (io.Reader, error)
+	GetRuns() ([]ark.Run, error)
+	GetRun(id string) (ark.Run, error)
+	GetRunEvents(ctx context.Context, id string) (<-chan cqrs.Envelope, error)
 }
+
+// ErrRunFinished is returned when the events of a run are requested after it finished
+var ErrRunFinished = errors.New("the run has already finished")
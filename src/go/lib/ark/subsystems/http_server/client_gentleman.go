@@ -1,9 +1,13 @@
 package http_server
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server/api_errors"
 
@@ -13,9 +17,13 @@ import (
 	"gopkg.in/h2non/gentleman.v2"
 
 	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs"
 	"github.com/myfintech/ark/src/go/lib/dag"
 )
 
+// maxRunEventSize the largest server-sent event the client accepts
+const maxRunEventSize = 10 * 1024 * 1024
+
 // ClientGentleman defines the gentleman.ClientGentleman to be passed around
 type ClientGentleman struct {
 	client *gentleman.Client
@@ -67,12 +75,26 @@ func (c ClientGentleman) AddTarget(target ark.RawTarget) (ark.RawArtifact, error
 
 // GetTargets gets all of the targets from the database and returns them as a slice of ark.Targets
 func (c ClientGentleman) GetTargets() ([]ark.RawTarget, error) {
+	return c.GetTargetsByLabels(ark.LabelSelector{})
+}
+
+// GetTargetsByLabels gets the targets from the database that satisfy the label selector
+func (c ClientGentleman) GetTargetsByLabels(labels ark.LabelSelector) ([]ark.RawTarget, error) {
 	var targets []ark.RawTarget
 
-	res, err := c.client.Request().
+	req := c.client.Request().
 		Path("/targets").
-		Method("GET").
-		Send()
+		Method("GET")
+
+	if len(labels.Include) > 0 {
+		req.AddQuery("label", strings.Join(labels.Include, ","))
+	}
+
+	if len(labels.Exclude) > 0 {
+		req.AddQuery("excludeLabel", strings.Join(labels.Exclude, ","))
+	}
+
+	res, err := req.Send()
 
 	defer func() {
 		_ = res.Close()
@@ -219,3 +241,125 @@ func (c ClientGentleman) GetLogsByKey(logKey string) (io.Reader, error) {
 
 	return res, nil
 }
+
+// GetRuns retrieves the recorded history of graph runs
+func (c ClientGentleman) GetRuns() ([]ark.Run, error) {
+	var runs []ark.Run
+
+	res, err := c.client.Request().
+		Path("/runs").
+		Method(http.MethodGet).
+		Send()
+
+	defer func() {
+		_ = res.Close()
+	}()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !res.Ok {
+		return nil, errors.Errorf("Request error: %v", res.StatusCode)
+	}
+
+	return runs, res.JSON(&runs)
+}
+
+// GetRun retrieves a recorded run by its subscription id
+func (c ClientGentleman) GetRun(id string) (ark.Run, error) {
+	var run ark.Run
+
+	res, err := c.client.Request().
+		Path(fmt.Sprintf("/runs/%s", id)).
+		Method(http.MethodGet).
+		Send()
+
+	defer func() {
+		_ = res.Close()
+	}()
+
+	if err != nil {
+		return run, err
+	}
+
+	if !res.Ok {
+		errRes := new(api_errors.APIError)
+		if err = res.JSON(errRes); err != nil {
+			return run, err
+		}
+
+		if errRes.Code == "" {
+			return run, errors.Errorf("Request error: %v", res.StatusCode)
+		}
+
+		return run, errRes
+	}
+
+	return run, res.JSON(&run)
+}
+
+// GetRunEvents follows the server-sent event stream of a run until it succeeds or fails
+// The channel is closed when the stream ends, when the context is canceled or when the connection to the server is lost
+// ErrRunFinished is returned if the run already finished
+func (c ClientGentleman) GetRunEvents(ctx context.Context, id string) (<-chan cqrs.Envelope, error) {
+	res, err := c.client.Request().
+		Path(fmt.Sprintf("/runs/%s/events", id)).
+		Method(http.MethodGet).
+		Send()
+	if err != nil {
+		return nil, err
+	}
+
+	if !res.Ok {
+		_ = res.Close()
+		return nil, errors.Errorf("Request error: %v", res.StatusCode)
+	}
+
+	if res.StatusCode == http.StatusNoContent {
+		_ = res.Close()
+		return nil, ErrRunFinished
+	}
+
+	stream := make(chan cqrs.Envelope)
+	done := make(chan struct{})
+
+	// closing the response unblocks the scanner when the context is canceled
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		_ = res.Close()
+	}()
+
+	go func() {
+		defer close(stream)
+		defer close(done)
+
+		scanner := bufio.NewScanner(res)
+		scanner.Buffer(make([]byte, 64*1024), maxRunEventSize)
+
+		var data bytes.Buffer
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if data.Len() == 0 {
+					continue
+				}
+				envelope := cqrs.NewEnvelope(cqrs.FromData(data.Bytes()))
+				data.Reset()
+				select {
+				case stream <- envelope:
+				case <-ctx.Done():
+					return
+				}
+			case strings.HasPrefix(line, "data:"):
+				data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			}
+		}
+	}()
+
+	return stream, nil
+}
@@ -0,0 +1,33 @@
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+)
+
+// Path the path the dashboard is served from by the host server
+const Path = "/dashboard"
+
+// assets embeds the single page dashboard with the binary at compile time
+//
+//go:embed static
+var assets embed.FS
+
+// New returns a handler serving the dashboard assets
+// The dashboard is a static page that reads the graph, the runs and the port forwards from the host server API
+func New() fiber.Handler {
+	static, err := fs.Sub(assets, "static")
+	if err != nil {
+		// the static dir is embedded at compile time so this can't fail at runtime
+		panic(err)
+	}
+
+	return filesystem.New(filesystem.Config{
+		Root:  http.FS(static),
+		Index: "index.html",
+	})
+}
@@ -0,0 +1,370 @@
+// The dashboard only reads from the host server API:
+//   GET /targets and GET /graph/edges     the loaded graph
+//   GET /runs and GET /runs/:id           the recorded runs and the status of their targets
+//   GET /runs/:id/events                  server-sent graph events, used to refresh a running run
+//   GET /runs/:id/logs?targetKey=         the followed log of a run or of one of its targets
+//   GET /ports                            the status of the port forwards
+"use strict";
+
+const RUNS_INTERVAL = 5000;
+const PORTS_INTERVAL = 3000;
+const DONE = ["cached", "skipped", "success", "failed", "failure_allowed", "blocked"];
+
+const state = {
+  targets: [],
+  edges: [],
+  runs: [],
+  run: null,
+  selectedRunId: null,
+  pinned: false,
+  selectedTargetKey: null,
+  events: null,
+  logs: null,
+  logsKey: null,
+};
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  Object.entries(attrs || {}).forEach(([name, value]) => {
+    if (name.startsWith("on")) {
+      node.addEventListener(name.slice(2), value);
+    } else {
+      node.setAttribute(name, value);
+    }
+  });
+  children.forEach((child) => node.append(child));
+  return node;
+}
+
+async function getJSON(path) {
+  const response = await fetch(path);
+  if (!response.ok) {
+    throw new Error(`${path} responded with ${response.status}`);
+  }
+  return response.json();
+}
+
+// followLines calls onLine for every line of a streamed response until the stream ends or the signal aborts
+async function followLines(path, signal, onLine) {
+  const response = await fetch(path, { signal });
+  if (!response.ok) {
+    throw new Error(`${path} responded with ${response.status}`);
+  }
+  if (!response.body) {
+    return;
+  }
+
+  const reader = response.body.getReader();
+  const decoder = new TextDecoder();
+  let buffered = "";
+  for (;;) {
+    const { value, done } = await reader.read();
+    if (done) {
+      break;
+    }
+    buffered += decoder.decode(value, { stream: true });
+    const lines = buffered.split("\n");
+    buffered = lines.pop();
+    lines.forEach(onLine);
+  }
+  if (buffered) {
+    onLine(buffered);
+  }
+}
+
+function targetKey(target) {
+  let file = target.file;
+  if (target.realm && file.startsWith(target.realm)) {
+    file = file.slice(target.realm.length).replace(/^\/+/, "");
+  }
+  return `${file}:${target.name}`;
+}
+
+function formatDuration(from, to) {
+  const start = Date.parse(from);
+  const end = Date.parse(to);
+  if (!start || start < 0 || !end || end < 0 || end < start) {
+    return "";
+  }
+  return `${((end - start) / 1000).toFixed(1)}s`;
+}
+
+// layers groups the targets by the length of their longest dependency chain so dependencies render left of their dependents
+function layers() {
+  const dependencies = {};
+  state.edges.forEach((edge) => {
+    (dependencies[edge.src] = dependencies[edge.src] || []).push(edge.dst);
+  });
+
+  const depth = {};
+  const visit = (key, path) => {
+    if (depth[key] !== undefined) {
+      return depth[key];
+    }
+    if (path.has(key)) {
+      return 0;
+    }
+    path.add(key);
+    depth[key] = Math.max(-1, ...(dependencies[key] || []).map((dependency) => visit(dependency, path))) + 1;
+    path.delete(key);
+    return depth[key];
+  };
+
+  const grouped = [];
+  state.targets.forEach((target) => {
+    const key = targetKey(target);
+    const layer = visit(key, new Set());
+    (grouped[layer] = grouped[layer] || []).push({ key, target, dependencies: dependencies[key] || [] });
+  });
+  return grouped.filter(Boolean).map((layer) => layer.sort((a, b) => a.key.localeCompare(b.key)));
+}
+
+function renderGraph() {
+  const runTargets = {};
+  ((state.run && state.run.targets) || []).forEach((target) => {
+    runTargets[target.key] = target;
+  });
+
+  document.getElementById("graph-title").textContent = state.run
+    ? `Graph — run ${state.run.id} (${state.run.status})`
+    : "Graph";
+
+  const graph = document.getElementById("graph");
+  graph.replaceChildren(
+    ...layers().map((layer) =>
+      el(
+        "div",
+        { class: "layer" },
+        ...layer.map(({ key, target, dependencies }) => {
+          const record = runTargets[key];
+          const classes = ["target"];
+          classes.push(record ? `status-${record.status}` : "idle");
+          if (key === state.selectedTargetKey) {
+            classes.push("selected");
+          }
+
+          const details = record
+            ? [record.status, record.attempts > 1 ? `${record.attempts} attempts` : "", formatDuration(record.startedAt, record.finishedAt)]
+            : ["not in run"];
+
+          const card = el(
+            "div",
+            { class: classes.join(" "), title: (record && record.error) || "", onclick: () => selectTarget(key) },
+            el("div", { class: "key" }, key),
+            el("div", { class: "muted" }, [target.type, ...details].filter(Boolean).join(" · "))
+          );
+          if (dependencies.length) {
+            card.append(el("div", { class: "deps muted" }, `after ${dependencies.join(", ")}`));
+          }
+          return card;
+        })
+      )
+    )
+  );
+}
+
+function renderRuns() {
+  const runs = document.getElementById("runs");
+  runs.replaceChildren(
+    ...state.runs.map((run) =>
+      el(
+        "li",
+        {
+          class: [`status-${run.status}`, run.id === state.selectedRunId ? "selected" : ""].join(" "),
+          onclick: () => {
+            state.pinned = true;
+            selectRun(run.id);
+          },
+        },
+        el("div", {}, run.targetKeys.join(", ")),
+        el(
+          "div",
+          { class: "muted" },
+          [run.status, new Date(run.startedAt).toLocaleTimeString(), formatDuration(run.startedAt, run.finishedAt)]
+            .filter(Boolean)
+            .join(" · ")
+        )
+      )
+    )
+  );
+}
+
+async function refreshGraph() {
+  const [targets, edges] = await Promise.all([getJSON("/targets"), getJSON("/graph/edges")]);
+  state.targets = targets || [];
+  state.edges = edges || [];
+  renderGraph();
+}
+
+async function refreshRuns() {
+  state.runs = (await getJSON("/runs")) || [];
+  // until a run is clicked the dashboard follows the most recent running run
+  if (!state.pinned && state.runs.length) {
+    const running = state.runs.find((run) => !DONE.includes(run.status));
+    if (running || !state.selectedRunId) {
+      selectRun((running || state.runs[0]).id);
+    }
+  }
+  renderRuns();
+}
+
+async function refreshRun() {
+  if (!state.selectedRunId) {
+    return;
+  }
+  state.run = await getJSON(`/runs/${state.selectedRunId}`);
+  renderGraph();
+
+  // the log of a target is only created once the graph walker reaches it
+  if (!state.logs) {
+    followLogs();
+  }
+}
+
+async function refreshPorts() {
+  const forwards = (await getJSON("/ports")) || [];
+  const body = document.querySelector("#ports tbody");
+  if (!forwards.length) {
+    body.replaceChildren(el("tr", {}, el("td", { class: "muted", colspan: "3" }, "no ports are forwarded")));
+    return;
+  }
+  body.replaceChildren(
+    ...forwards.map((forward) =>
+      el(
+        "tr",
+        { title: forward.error || "" },
+        el("td", {}, `${forward.namespace}/${forward.pod}`),
+        el("td", {}, (forward.ports || []).join(", ")),
+        el("td", { class: `forward-${forward.status}` }, forward.status)
+      )
+    )
+  );
+}
+
+// followEvents refreshes the selected run every time the host server publishes one of its events
+async function followEvents() {
+  if (state.events) {
+    state.events.abort();
+  }
+  const controller = new AbortController();
+  state.events = controller;
+
+  let pending = null;
+  const scheduleRefresh = () => {
+    if (!pending) {
+      pending = setTimeout(() => {
+        pending = null;
+        refreshRun().catch(showError);
+      }, 250);
+    }
+  };
+
+  try {
+    await followLines(`/runs/${state.selectedRunId}/events`, controller.signal, (line) => {
+      if (line.startsWith("data:")) {
+        scheduleRefresh();
+      }
+    });
+  } catch (err) {
+    if (controller.signal.aborted) {
+      return;
+    }
+    showError(err);
+  }
+
+  // the stream ends once the run finished, the recorder may apply the last events after they were streamed
+  if (state.events === controller) {
+    state.events = null;
+    setTimeout(() => {
+      refreshRun().catch(showError);
+      refreshRuns().catch(showError);
+    }, 500);
+  }
+}
+
+async function followLogs() {
+  if (!state.selectedRunId) {
+    return;
+  }
+  const key = `${state.selectedRunId}/${state.selectedTargetKey || ""}`;
+  if (state.logs && state.logsKey === key) {
+    return;
+  }
+  if (state.logs) {
+    state.logs.abort();
+  }
+
+  const controller = new AbortController();
+  state.logs = controller;
+  state.logsKey = key;
+
+  const title = document.getElementById("logs-title");
+  const logs = document.getElementById("logs");
+  title.textContent = state.selectedTargetKey ? `Logs — ${state.selectedTargetKey}` : "Logs — run";
+  logs.textContent = "";
+  logs.classList.remove("muted");
+
+  let path = `/runs/${state.selectedRunId}/logs`;
+  if (state.selectedTargetKey) {
+    path += `?targetKey=${encodeURIComponent(state.selectedTargetKey)}`;
+  }
+
+  try {
+    await followLines(path, controller.signal, (line) => {
+      const follow = logs.parentElement.scrollTop + logs.parentElement.clientHeight >= logs.parentElement.scrollHeight - 8;
+      logs.append(`${line}\n`);
+      if (follow) {
+        logs.parentElement.scrollTop = logs.parentElement.scrollHeight;
+      }
+    });
+  } catch (err) {
+    if (controller.signal.aborted) {
+      return;
+    }
+    logs.classList.add("muted");
+    logs.textContent = "no logs were written yet";
+  }
+
+  // a missing log is requested again on the next refresh of the run
+  if (state.logs === controller) {
+    state.logs = null;
+  }
+}
+
+function selectRun(id) {
+  if (state.selectedRunId === id) {
+    return;
+  }
+  state.selectedRunId = id;
+  state.selectedTargetKey = null;
+  state.run = null;
+  renderRuns();
+  refreshRun().catch(showError);
+  followEvents();
+  followLogs();
+}
+
+function selectTarget(key) {
+  state.selectedTargetKey = state.selectedTargetKey === key ? null : key;
+  renderGraph();
+  followLogs();
+}
+
+function showError(err) {
+  document.getElementById("connection").textContent = err.message;
+}
+
+function poll(fn, interval) {
+  const run = () =>
+    fn()
+      .then(() => {
+        document.getElementById("connection").textContent = "";
+      })
+      .catch(showError);
+  run();
+  setInterval(run, interval);
+}
+
+poll(refreshGraph, RUNS_INTERVAL);
+poll(refreshRuns, RUNS_INTERVAL);
+poll(refreshPorts, PORTS_INTERVAL);
@@ -5,74 +5,138 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server/api_errors"
+	"github.com/pkg/errors"
 
 	"github.com/myfintech/ark/src/go/lib/logz"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// logsHeartbeat the interval at which an idle log stream checks that the client is still connected
+const logsHeartbeat = 15 * time.Second
+
 // NewLogsHandler streams logs back to the HTTP client
 func NewLogsHandler(logFilePath string, logger logz.FieldLogger) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		path := logFilePath
 		if c.Params("log_key") != "" {
 			var err error
-			logFilePath, err = logz.SuggestedFilePath("ark/graph", fmt.Sprintf("%s/run.log", c.Params("log_key")))
+			path, err = logz.SuggestedFilePath("ark/graph", fmt.Sprintf("%s/run.log", c.Params("log_key")))
 			if err != nil {
 				return api_errors.InternalServerError.
 					WithErr(err)
 			}
 		}
-		watcher, err := fsnotify.NewWatcher()
+		return followLogFile(c, path, logger)
+	}
+}
+
+// NewRunLogsHandler streams the logs of a run back to the HTTP client
+// The targetKey query parameter selects the log of a single target instead of the log of the graph walker
+func NewRunLogsHandler(logger logz.FieldLogger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		runLogDir, err := logz.SuggestedFilePath("ark/graph", c.Params("id"))
 		if err != nil {
 			return api_errors.InternalServerError.
 				WithErr(err)
 		}
-		if err = watcher.Add(logFilePath); err != nil {
-			return api_errors.InternalServerError.
+
+		targetKey := c.Query("targetKey")
+		if targetKey == "" {
+			return followLogFile(c, filepath.Join(runLogDir, "run.log"), logger)
+		}
+
+		// target logs are named [short-hash]_[normalized__key].log by the graph walker
+		matches, err := filepath.Glob(filepath.Join(runLogDir, fmt.Sprintf("*_%s.log", strings.Replace(targetKey, "/", "__", -1))))
+		if err != nil {
+			return api_errors.BadRequest.
 				WithErr(err)
 		}
+		if len(matches) == 0 {
+			return api_errors.NotFoundError.
+				WithErr(errors.Errorf("no log was written for %s in run %s", targetKey, c.Params("id")))
+		}
 
-		c.Response().SetBodyStreamWriter(func(w *bufio.Writer) {
-			defer func() {
-				_ = watcher.Close()
-			}()
+		return followLogFile(c, matches[0], logger)
+	}
+}
 
-			file, openErr := os.OpenFile(logFilePath, os.O_RDONLY, 0644)
-			if openErr != nil {
-				logger.Error(openErr)
-				return
-			}
-			defer func() {
-				_ = file.Close()
-			}()
-
-			reader := bufio.NewReader(file)
-			for {
-				line, _, readErr := reader.ReadLine()
-				if readErr == io.EOF {
-					<-watcher.Events
-					continue
-				}
-				if readErr != nil {
-					logger.Error(readErr)
-					return
-				}
+// followLogFile writes the contents of a log file to the response and keeps writing the lines appended to it
+func followLogFile(c *fiber.Ctx, logFilePath string, logger logz.FieldLogger) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return api_errors.InternalServerError.
+			WithErr(err)
+	}
+	if err = watcher.Add(logFilePath); err != nil {
+		_ = watcher.Close()
+		return api_errors.InternalServerError.
+			WithErr(err)
+	}
 
-				if _, err = w.WriteString(fmt.Sprintf("%s\n", line)); err != nil {
-					logger.Error(err)
-					return
-				}
+	c.Response().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer func() {
+			_ = watcher.Close()
+		}()
 
-				if err = w.Flush(); err != nil {
-					logger.Error(err)
+		file, openErr := os.OpenFile(logFilePath, os.O_RDONLY, 0644)
+		if openErr != nil {
+			logger.Error(openErr)
+			return
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+
+		heartbeat := time.NewTicker(logsHeartbeat)
+		defer heartbeat.Stop()
+
+		reader := bufio.NewReader(file)
+		for {
+			line, _, readErr := reader.ReadLine()
+			if readErr == io.EOF {
+				// the heartbeat writes an empty line while the log is idle so a disconnected client is noticed
+				select {
+				case _, ok := <-watcher.Events:
+					if !ok {
+						return
+					}
+				case watchErr := <-watcher.Errors:
+					logger.Error(watchErr)
 					return
+				case <-heartbeat.C:
+					if _, err = w.WriteString("\n"); err != nil {
+						return
+					}
+					// a failed flush means the client disconnected
+					if err = w.Flush(); err != nil {
+						return
+					}
 				}
+				continue
+			}
+			if readErr != nil {
+				logger.Error(readErr)
+				return
 			}
-		})
 
-		return nil
-	}
+			if _, err = w.WriteString(fmt.Sprintf("%s\n", line)); err != nil {
+				logger.Error(err)
+				return
+			}
+
+			if err = w.Flush(); err != nil {
+				logger.Error(err)
+				return
+			}
+		}
+	})
+
+	return nil
 }
@@ -0,0 +1,14 @@
+package http_handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/port_binder"
+)
+
+// NewListPortForwardsHandler returns the status of the ports forwarded by the port binder
+func NewListPortForwardsHandler(forwards *port_binder.Forwards) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(forwards.List())
+	}
+}
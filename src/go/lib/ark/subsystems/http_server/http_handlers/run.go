@@ -31,6 +31,14 @@ func NewRunHandler(store ark.Store, _ logz.FieldLogger, broker cqrs.Broker) fibe
 				WithErr(err)
 		}
 
+		if cmd.RunID != "" {
+			var err error
+			if subscriptionId, err = uuid.Parse(cmd.RunID); err != nil {
+				return api_errors.BadRequest.
+					WithErr(err)
+			}
+		}
+
 		for _, key := range cmd.TargetKeys {
 			_, err := store.GetTargetByKey(key)
 			if err != nil {
@@ -0,0 +1,105 @@
+package http_handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/events"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/topics"
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server/api_errors"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+// runEventsHeartbeat the interval of the comments written to an idle event stream so disconnected clients are detected
+const runEventsHeartbeat = 15 * time.Second
+
+// NewRunEventsHandler streams the graph runner and graph walker events of a run as server-sent events
+// Every event is a cloud event encoded as json, the stream ends after the run succeeds or fails
+// When the broker is a cqrs.Replayer the events published before the request are streamed first
+// A run that already finished responds with 204 No Content
+func NewRunEventsHandler(store ark.RunStore, logger logz.FieldLogger, broker cqrs.Broker) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// the id is copied because fiber reuses its buffers after the handler returns and the stream is written
+		id := utils.CopyString(c.Params("id"))
+
+		// the subscription is created before the run is looked up so no event published in between is lost
+		// a broker that retains the events of a run replays them so a late client receives the run from the start
+		ctx, cancel := context.WithCancel(context.Background())
+		var stream <-chan cqrs.Envelope
+		var err error
+		if replayer, ok := broker.(cqrs.Replayer); ok {
+			stream, err = replayer.Replay(ctx, topics.GraphEvents, cqrs.RouteKey(id))
+		} else {
+			stream, err = broker.Subscribe(ctx, topics.GraphEvents, nil)
+		}
+		if err != nil {
+			cancel()
+			return api_errors.InternalServerError.
+				WithErr(err)
+		}
+
+		if run, getErr := store.GetRunByID(id); getErr == nil && run.Status.Done() {
+			cancel()
+			return c.SendStatus(http.StatusNoContent)
+		}
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+
+		c.Response().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer cancel()
+
+			heartbeat := time.NewTicker(runEventsHeartbeat)
+			defer heartbeat.Stop()
+
+			for {
+				select {
+				case <-heartbeat.C:
+					if _, err = w.WriteString(": heartbeat\n\n"); err != nil {
+						return
+					}
+				case envelope, ok := <-stream:
+					// the broker closed the subscription so no event of the run can follow
+					if !ok {
+						return
+					}
+					if envelope.Error != nil || envelope.Subject() != id {
+						continue
+					}
+
+					data, marshalErr := envelope.MarshalJSON()
+					if marshalErr != nil {
+						logger.Error(marshalErr)
+						continue
+					}
+
+					if _, err = w.WriteString(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", envelope.ID(), envelope.Type(), data)); err != nil {
+						return
+					}
+
+					switch envelope.TypeKey() {
+					case events.GraphRunnerSuccess, events.GraphRunnerFailed:
+						_ = w.Flush()
+						return
+					}
+				}
+
+				// a failed flush means the client disconnected
+				if err = w.Flush(); err != nil {
+					return
+				}
+			}
+		})
+
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+package http_handlers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/events"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/topics"
+	"github.com/myfintech/ark/src/go/lib/ark/storage/memory"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+func TestNewRunEventsHandler(t *testing.T) {
+	store := new(memory.Store)
+	broker := cqrs.NewMockBroker()
+	broker.On("Subscribe", topics.GraphEvents)
+	broker.On("Publish", topics.GraphEvents)
+
+	app := fiber.New()
+	app.Get("/runs/:id/events", NewRunEventsHandler(store, new(logz.NoOpLogger), broker))
+
+	// the mock broker hands the handler the subscription created here so the events are buffered before the request
+	_, err := broker.Subscribe(context.Background(), topics.GraphEvents, nil)
+	require.NoError(t, err)
+
+	for _, envelope := range []cqrs.Envelope{
+		cqrs.NewDefaultEnvelope(cqrs.WithSource("test"), events.GraphWalkerActionStartedType, cqrs.WithSubject("run-2")),
+		cqrs.NewDefaultEnvelope(cqrs.WithSource("test"), events.GraphWalkerActionStartedType, cqrs.WithSubject("run-1")),
+		cqrs.NewDefaultEnvelope(cqrs.WithSource("test"), events.GraphRunnerSuccessType, cqrs.WithSubject("run-1")),
+		cqrs.NewDefaultEnvelope(cqrs.WithSource("test"), events.GraphWalkerActionSuccessType, cqrs.WithSubject("run-1")),
+	} {
+		require.NoError(t, broker.Publish(topics.GraphEvents, envelope))
+	}
+
+	t.Run("should stream the events of the run until it finishes", func(t *testing.T) {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, "/runs/run-1/events", nil), -1)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "text/event-stream", res.Header.Get(fiber.HeaderContentType))
+
+		body, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+
+		var types []string
+		for _, line := range strings.Split(string(body), "\n") {
+			if strings.HasPrefix(line, "event: ") {
+				types = append(types, strings.TrimPrefix(line, "event: "))
+			}
+		}
+		require.Equal(t, []string{
+			events.GraphWalkerActionStarted.String(),
+			events.GraphRunnerSuccess.String(),
+		}, types)
+	})
+
+	t.Run("should not stream a finished run", func(t *testing.T) {
+		require.NoError(t, store.SaveRun(ark.Run{ID: "run-3", Status: ark.RunStatusSuccess}))
+
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, "/runs/run-3/events", nil), -1)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusNoContent, res.StatusCode)
+	})
+	t.Run("should end the stream when the broker closes the subscription", func(t *testing.T) {
+		closingApp := fiber.New()
+		closingApp.Get("/runs/:id/events", NewRunEventsHandler(store, new(logz.NoOpLogger), closedBroker{broker}))
+
+		res, err := closingApp.Test(httptest.NewRequest(http.MethodGet, "/runs/run-4/events", nil), 5000)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		body, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Empty(t, body)
+	})
+}
+
+// closedBroker a broker whose subscriptions are closed before they deliver any message
+type closedBroker struct {
+	cqrs.Broker
+}
+
+func (closedBroker) Subscribe(_ context.Context, _ cqrs.RouteKey, _ *time.Duration) (<-chan cqrs.Envelope, error) {
+	stream := make(chan cqrs.Envelope)
+	close(stream)
+	return stream, nil
+}
@@ -0,0 +1,38 @@
+package http_handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server/api_errors"
+)
+
+// NewListRunsHandler returns the recorded runs from the most to the least recently started
+func NewListRunsHandler(store ark.RunStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		runs, err := store.GetRuns()
+		if err != nil {
+			return api_errors.InternalServerError.
+				WithErr(err)
+		}
+
+		if runs == nil {
+			runs = []ark.Run{}
+		}
+
+		return c.JSON(runs)
+	}
+}
+
+// NewGetRunHandler returns a recorded run by its subscription id
+func NewGetRunHandler(store ark.RunStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		run, err := store.GetRunByID(c.Params("id"))
+		if err != nil {
+			return api_errors.NotFoundError.
+				WithErr(err)
+		}
+
+		return c.JSON(run)
+	}
+}
@@ -1,11 +1,15 @@
 package http_handlers
 
 import (
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/myfintech/ark/src/go/lib/ark"
 	api_errors2 "github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server/api_errors"
 )
 
+// NewListTargetsHandler lists the targets in the store
+// The label and excludeLabel query parameters accept comma separated label selectors to filter the targets
 func NewListTargetsHandler(store ark.Store) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 
@@ -26,6 +30,16 @@ func NewListTargetsHandler(store ark.Store) fiber.Handler {
 				WithErr(err)
 		}
 
-		return c.JSON(targets)
+		labels := ark.NewLabelSelector(
+			strings.Split(c.Query("label", ""), ","),
+			strings.Split(c.Query("excludeLabel", ""), ","),
+		)
+
+		filtered := labels.Filter(targets)
+		if filtered == nil {
+			filtered = []ark.RawTarget{}
+		}
+
+		return c.JSON(filtered)
 	}
 }
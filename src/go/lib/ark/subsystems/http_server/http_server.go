@@ -15,12 +15,14 @@ import (
 	"github.com/myfintech/ark/src/go/lib/ark/cqrs/topics"
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems"
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server/api_errors"
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server/dashboard"
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server/http_handlers"
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/port_binder"
 	"github.com/myfintech/ark/src/go/lib/logz"
 )
 
 // New creates a new fiber server and storage interface
-func New(store ark.Store, logger logz.FieldLogger, broker cqrs.Broker, logFilePath string) *fiber.App {
+func New(store ark.Store, logger logz.FieldLogger, broker cqrs.Broker, logFilePath string, forwards *port_binder.Forwards) *fiber.App {
 	// setup the new fiber server and append its configuration
 	server := fiber.New(fiber.Config{
 		DisableStartupMessage: true,
@@ -69,6 +71,30 @@ func New(store ark.Store, logger logz.FieldLogger, broker cqrs.Broker, logFilePa
 	// triggers an ark execution of a given target
 	server.Post("/run", http_handlers.NewRunHandler(store, logger, broker))
 
+	// GET /runs
+	// returns the recorded history of graph runs
+	server.Get("/runs", http_handlers.NewListRunsHandler(store))
+
+	// GET /runs/:id
+	// returns a recorded run with the status and timings of every target
+	server.Get("/runs/:id", http_handlers.NewGetRunHandler(store))
+
+	// GET /runs/:id/events
+	// returns a server-sent event stream of the graph runner and graph walker events of a run
+	server.Get("/runs/:id/events", http_handlers.NewRunEventsHandler(store, logger, broker))
+
+	// GET /runs/:id/logs?targetKey=
+	// returns a followed log stream of a run or of one of the targets it walked
+	server.Get("/runs/:id/logs", http_handlers.NewRunLogsHandler(logger))
+
+	// GET /ports
+	// returns the status of the ports forwarded by the port binder
+	server.Get("/ports", http_handlers.NewListPortForwardsHandler(forwards))
+
+	// GET /dashboard
+	// returns the browser dashboard of the host server
+	server.Use(dashboard.Path, dashboard.New())
+
 	// GET /server/logs
 	// returns a followed log stream of the server logs
 	server.Get("/server/logs", http_handlers.NewLogsHandler(logFilePath, logger))
@@ -85,14 +111,20 @@ func New(store ark.Store, logger logz.FieldLogger, broker cqrs.Broker, logFilePa
 }
 
 // NewSubsystem stands up an http_server as a subsystem
-func NewSubsystem(addr, logFile string, store ark.Store, logger logz.FieldLogger, broker cqrs.Broker) *subsystems.Process {
+func NewSubsystem(
+	addr, logFile string,
+	store ark.Store,
+	logger logz.FieldLogger,
+	broker cqrs.Broker,
+	forwards *port_binder.Forwards,
+) *subsystems.Process {
 	logger = logger.Child(logz.WithFields(logz.Fields{
 		"system": topics.HTTPServer.String(),
 	}))
 	return &subsystems.Process{
 		Name: topics.HTTPServer.String(),
 		Factory: func(wg *sync.WaitGroup, ctx context.Context) func() error {
-			server := New(store, logger, broker, logFile)
+			server := New(store, logger, broker, logFile, forwards)
 			eg, egCtx := errgroup.WithContext(ctx)
 
 			eg.Go(func() error {
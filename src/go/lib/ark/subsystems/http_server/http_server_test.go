@@ -88,6 +88,12 @@ func TestHostServerWithClient(t *testing.T) {
 			require.NotEmpty(t, targetSlice)
 		})
 
+		t.Run("should be able to get targets by label", func(t *testing.T) {
+			targetSlice, er := client.GetTargetsByLabels(ark.NewLabelSelector([]string{"team=payments"}, nil))
+			require.NoError(t, er)
+			require.Empty(t, targetSlice)
+		})
+
 		t.Run("should be able to connect targets in the graph", func(t *testing.T) {
 			_, er := client.ConnectTargets(ark.GraphEdge{
 				Src: "test",
@@ -107,6 +113,21 @@ func TestHostServerWithClient(t *testing.T) {
 			// require.NotEmpty(t, edges)
 		})
 
+		t.Run("should be able to get recorded runs", func(t *testing.T) {
+			require.NoError(t, store.SaveRun(ark.Run{ID: "run-1", Status: ark.RunStatusSuccess}))
+
+			runs, er := client.GetRuns()
+			require.NoError(t, er)
+			require.Len(t, runs, 1)
+
+			run, er := client.GetRun("run-1")
+			require.NoError(t, er)
+			require.Equal(t, ark.RunStatusSuccess, run.Status)
+
+			_, er = client.GetRun("does-not-exist")
+			require.Error(t, er)
+		})
+
 		t.Run("should be able to run a target", func(t *testing.T) {
 			_, er := client.AddTarget(ark.RawTarget{
 				Name:  "test",
@@ -0,0 +1,129 @@
+package port_binder
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/myfintech/ark/src/go/lib/kube"
+)
+
+// ForwardStatus the state of the ports forwarded to a pod
+type ForwardStatus string
+
+const (
+	// ForwardStatusBinding the port forward was started and is not ready yet
+	ForwardStatusBinding ForwardStatus = "binding"
+
+	// ForwardStatusBound the ports are forwarded to the pod
+	ForwardStatusBound ForwardStatus = "bound"
+
+	// ForwardStatusFailed the port forward could not be established or was dropped
+	ForwardStatusFailed ForwardStatus = "failed"
+)
+
+// Forward the status of the ports forwarded to a single pod
+type Forward struct {
+	Namespace string        `json:"namespace"`
+	Pod       string        `json:"pod"`
+	Ports     []string      `json:"ports"`
+	Status    ForwardStatus `json:"status"`
+	Error     string        `json:"error,omitempty"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// Forwards tracks the port forwards of the port binder so their status can be reported
+// A single instance is shared by the port binder subsystems, a nil *Forwards tracks nothing
+type Forwards struct {
+	mutex    sync.RWMutex
+	forwards map[string]Forward
+}
+
+// NewForwards creates an empty port forward tracker
+func NewForwards() *Forwards {
+	return &Forwards{forwards: make(map[string]Forward)}
+}
+
+// List returns the tracked port forwards sorted by namespace and pod
+func (f *Forwards) List() []Forward {
+	forwards := make([]Forward, 0)
+	if f == nil {
+		return forwards
+	}
+
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	for _, forward := range f.forwards {
+		forwards = append(forwards, forward)
+	}
+	sort.Slice(forwards, func(i, j int) bool {
+		if forwards[i].Namespace != forwards[j].Namespace {
+			return forwards[i].Namespace < forwards[j].Namespace
+		}
+		return forwards[i].Pod < forwards[j].Pod
+	})
+	return forwards
+}
+
+func (f *Forwards) binding(opts *kube.ForwardingOptions) {
+	f.set(opts, ForwardStatusBinding, nil)
+}
+
+// bound only moves a forward out of the binding state so a forward that already failed is not reported as bound
+func (f *Forwards) bound(opts *kube.ForwardingOptions) {
+	if f == nil {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	key := forwardKey(opts)
+	if forward, ok := f.forwards[key]; ok && forward.Status == ForwardStatusBinding {
+		forward.Status = ForwardStatusBound
+		forward.UpdatedAt = time.Now()
+		f.forwards[key] = forward
+	}
+}
+
+// done removes a forward that was stopped and records the error of a forward that failed
+func (f *Forwards) done(opts *kube.ForwardingOptions, err error) {
+	if err != nil {
+		f.set(opts, ForwardStatusFailed, err)
+		return
+	}
+
+	if f == nil {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	delete(f.forwards, forwardKey(opts))
+}
+
+func (f *Forwards) set(opts *kube.ForwardingOptions, status ForwardStatus, err error) {
+	if f == nil {
+		return
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	forward := Forward{
+		Namespace: opts.Namespace,
+		Pod:       opts.Pod.Name,
+		Ports:     opts.Ports,
+		Status:    status,
+		UpdatedAt: time.Now(),
+	}
+	if err != nil {
+		forward.Error = err.Error()
+	}
+	f.forwards[forwardKey(opts)] = forward
+}
+
+func forwardKey(opts *kube.ForwardingOptions) string {
+	return opts.Namespace + "/" + opts.Pod.Name
+}
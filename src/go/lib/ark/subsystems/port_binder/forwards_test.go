@@ -0,0 +1,61 @@
+package port_binder
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/myfintech/ark/src/go/lib/kube"
+)
+
+func TestForwards(t *testing.T) {
+	newOptions := func(pod string) *kube.ForwardingOptions {
+		return &kube.ForwardingOptions{
+			Namespace: "default",
+			Pod:       v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pod}},
+			Ports:     []string{"9000:9000"},
+		}
+	}
+
+	t.Run("should report the status of every forward", func(t *testing.T) {
+		forwards := NewForwards()
+		api, worker := newOptions("api"), newOptions("worker")
+
+		forwards.binding(worker)
+		forwards.binding(api)
+		forwards.bound(api)
+
+		list := forwards.List()
+		require.Len(t, list, 2)
+		require.Equal(t, "api", list[0].Pod)
+		require.Equal(t, ForwardStatusBound, list[0].Status)
+		require.Equal(t, []string{"9000:9000"}, list[0].Ports)
+		require.Equal(t, ForwardStatusBinding, list[1].Status)
+	})
+
+	t.Run("should keep failed forwards and remove stopped forwards", func(t *testing.T) {
+		forwards := NewForwards()
+		api, worker := newOptions("api"), newOptions("worker")
+
+		forwards.binding(api)
+		forwards.binding(worker)
+		forwards.done(api, errors.New("connection refused"))
+		forwards.bound(api)
+		forwards.bound(worker)
+		forwards.done(worker, nil)
+
+		list := forwards.List()
+		require.Len(t, list, 1)
+		require.Equal(t, ForwardStatusFailed, list[0].Status)
+		require.Equal(t, "connection refused", list[0].Error)
+	})
+
+	t.Run("should track nothing when nil", func(t *testing.T) {
+		var forwards *Forwards
+		forwards.binding(newOptions("api"))
+		require.Empty(t, forwards.List())
+	})
+}
@@ -30,6 +30,7 @@ func NewSubsystem(
 	broker cqrs.Broker,
 	logger logz.FieldLogger,
 	client kube.Client,
+	forwards *Forwards,
 ) *subsystems.Process {
 	logger = logger.Child(logz.WithFields(logz.Fields{
 		"system": topics.PortBinder.String(),
@@ -42,7 +43,7 @@ func NewSubsystem(
 			topics.PortBinderCommands,
 			broker,
 			logger,
-			newOnMessageFunc(broker, logger, client),
+			newOnMessageFunc(broker, logger, client, forwards),
 			newOnMessageErrFunc(broker, logger),
 			nil,
 		),
@@ -53,6 +54,7 @@ func K8sEchoHandler(
 	broker cqrs.Broker,
 	logger logz.FieldLogger,
 	client kube.Client,
+	forwards *Forwards,
 ) *subsystems.Process {
 	logger = logger.Child(logz.WithFields(logz.Fields{
 		"system": topics.PortBinder.String(),
@@ -65,7 +67,7 @@ func K8sEchoHandler(
 			topics.K8sEchoEvents,
 			broker,
 			logger,
-			newK8sEchoOnMessageFunc(broker, logger, client),
+			newK8sEchoOnMessageFunc(broker, logger, client, forwards),
 			newOnMessageErrFunc(broker, logger),
 			nil,
 		),
@@ -147,6 +149,7 @@ func newOnMessageFunc(
 	broker cqrs.Broker,
 	logger logz.FieldLogger,
 	client kube.Client,
+	forwards *Forwards,
 ) cqrs.OnMessageFunc {
 	logger.Info("ready")
 	state := newPortBinderState(logger)
@@ -192,11 +195,13 @@ func newOnMessageFunc(
 
 		state.addBindings("dont know yet", command, forwardOptions)
 
-		go forwardPorts(forwardOptions, logger)
+		forwards.binding(forwardOptions)
+		go forwardPorts(forwardOptions, logger, forwards)
 		go watchContextAndCancelForwarding(ctx, forwardOptions)
 
 		select {
 		case <-forwardOptions.ReadyChannel:
+			forwards.bound(forwardOptions)
 			logger.Infof("ports successfully bound %s", command.PortMap.ToPairs())
 		case err = <-forwardOptions.DoneChannel:
 			logger.Errorf("failed to bind ports %s %v", command.PortMap.ToPairs(), err)
@@ -216,6 +221,7 @@ func newK8sEchoOnMessageFunc(
 	broker cqrs.Broker,
 	logger logz.FieldLogger,
 	client kube.Client,
+	forwards *Forwards,
 ) cqrs.OnMessageFunc {
 	logger.Info("ready")
 	state := newPortBinderState(logger)
@@ -305,11 +311,13 @@ func newK8sEchoOnMessageFunc(
 		state.unbindExistingPorts(event.Name, command)
 		state.addBindings(event.Name, command, forwardOptions)
 
-		go forwardPorts(forwardOptions, logger)
+		forwards.binding(forwardOptions)
+		go forwardPorts(forwardOptions, logger, forwards)
 		go watchContextAndCancelForwarding(ctx, forwardOptions)
 
 		select {
 		case <-forwardOptions.ReadyChannel:
+			forwards.bound(forwardOptions)
 			logger.Infof(
 				"ports successfully bound %s for pod: %s",
 				command.PortMap.ToPairs(),
@@ -341,8 +349,10 @@ func watchContextAndCancelForwarding(ctx context.Context, opts *kube.ForwardingO
 	}
 }
 
-func forwardPorts(opts *kube.ForwardingOptions, logger logz.FieldLogger) {
+func forwardPorts(opts *kube.ForwardingOptions, logger logz.FieldLogger, forwards *Forwards) {
 	defer close(opts.DoneChannel)
 	logger.Infof("connecting to pod %s", opts.Pod.Name)
-	opts.DoneChannel <- kube.PortForward(*opts)
+	err := kube.PortForward(*opts)
+	forwards.done(opts, err)
+	opts.DoneChannel <- err
 }
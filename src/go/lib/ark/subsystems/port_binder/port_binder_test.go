@@ -47,8 +47,8 @@ func TestPortBinderSubsystem(t *testing.T) {
 	require.NoError(t, errK8sEchoInbox)
 
 	wg.Add(1)
-	eg.Go(NewSubsystem(broker, logger, sharedClients.K8s).Factory(wg, egCTX))
-	// eg.Go(K8sEchoHandler(broker, logger, sharedClients.K8s).Factory(wg, egCTX))
+	eg.Go(NewSubsystem(broker, logger, sharedClients.K8s, NewForwards()).Factory(wg, egCTX))
+	// eg.Go(K8sEchoHandler(broker, logger, sharedClients.K8s, NewForwards()).Factory(wg, egCTX))
 	wg.Wait()
 
 	// publish a message that the subsystem should react to
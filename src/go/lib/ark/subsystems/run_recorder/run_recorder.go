@@ -0,0 +1,367 @@
+package run_recorder
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/events"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/messages"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/topics"
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+// NewSubsystem factory function to return a run_recorder subsystem that records graph walker events
+func NewSubsystem(recorder *Recorder, logger logz.FieldLogger, broker cqrs.Broker) *subsystems.Process {
+	return newRecorderProcess(topics.GraphWalkerEvents, recorder, logger, broker)
+}
+
+// GraphRunnerHandler factory function to return a run_recorder subsystem that records graph runner events
+func GraphRunnerHandler(recorder *Recorder, logger logz.FieldLogger, broker cqrs.Broker) *subsystems.Process {
+	return newRecorderProcess(topics.GraphRunnerEvents, recorder, logger, broker)
+}
+
+func newRecorderProcess(topic cqrs.RouteKey, recorder *Recorder, logger logz.FieldLogger, broker cqrs.Broker) *subsystems.Process {
+	logger = logger.Child(logz.WithFields(logz.Fields{
+		"system": topics.RunRecorder.String(),
+	}))
+	return &subsystems.Process{
+		Name: topics.RunRecorder.With(topic).String(),
+		Factory: subsystems.Reactor(
+			topic,
+			broker,
+			logger,
+			func(ctx context.Context, msg cqrs.Envelope) error {
+				return recorder.Record(msg)
+			},
+			newOnMessageErrFunc(logger),
+			nil,
+		),
+	}
+}
+
+// recording is best effort and must never interfere with a run
+func newOnMessageErrFunc(logger logz.FieldLogger) cqrs.OnMessageErrorFunc {
+	return func(ctx context.Context, msg cqrs.Envelope, err error) error {
+		logger.Warnf("failed to record %s event for run %s %v", msg.Type(), msg.Subject(), err)
+		return nil
+	}
+}
+
+// finishedRunRetention how long a finished run is kept in memory so events that arrive after the end of the run are still applied to it
+var finishedRunRetention = time.Minute
+
+// Recorder applies graph runner and graph walker events to the run history of a store
+// The reactor processes messages concurrently so events may be applied out of order
+// Statuses therefore only move forward and timings never depend on the order events arrive
+// Runs are recorded in memory while they execute and only persisted when the status of the run changes
+type Recorder struct {
+	mutex    sync.Mutex
+	store    ark.RunStore
+	inFlight map[string]*recording
+}
+
+// recording a run that is being recorded in memory
+type recording struct {
+	mutex     sync.Mutex
+	run       ark.Run
+	saved     ark.RunStatus
+	persisted bool
+
+	// forgetting is true once the run finished and is scheduled to be removed from memory
+	forgetting bool
+}
+
+// NewRecorder creates a Recorder that persists runs to the given store
+func NewRecorder(store ark.RunStore) *Recorder {
+	return &Recorder{
+		store:    store,
+		inFlight: make(map[string]*recording),
+	}
+}
+
+// Record applies an event to the run identified by the subject of the envelope
+func (r *Recorder) Record(msg cqrs.Envelope) error {
+	if msg.Error != nil {
+		return errors.Wrap(msg.Error, "failed to deserialize incoming envelope")
+	}
+
+	if msg.Subject() == "" {
+		return nil
+	}
+
+	rec := r.recording(msg.Subject())
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+
+	if err := Apply(&rec.run, msg); err != nil {
+		return err
+	}
+
+	// events that only change a target are kept in memory until the status of the run changes
+	if rec.persisted && rec.saved == rec.run.Status && !rec.run.Status.Done() {
+		return nil
+	}
+
+	if err := r.store.SaveRun(snapshot(rec.run)); err != nil {
+		return err
+	}
+
+	rec.persisted = true
+	rec.saved = rec.run.Status
+
+	if rec.run.Status.Done() && !rec.forgetting {
+		rec.forgetting = true
+		id := rec.run.ID
+		time.AfterFunc(finishedRunRetention, func() {
+			r.forget(id, rec)
+		})
+	}
+	return nil
+}
+
+// recording returns the in memory recording of a run, it is loaded from the store if the run is not being recorded
+func (r *Recorder) recording(id string) *recording {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if rec, ok := r.inFlight[id]; ok {
+		return rec
+	}
+
+	rec := &recording{run: ark.Run{
+		ID:     id,
+		Status: ark.RunStatusRunning,
+	}}
+	if run, err := r.store.GetRunByID(id); err == nil {
+		rec.run = run
+		rec.saved = run.Status
+		rec.persisted = true
+	}
+	r.inFlight[id] = rec
+	return rec
+}
+
+// forget stops recording a finished run in memory
+func (r *Recorder) forget(id string, rec *recording) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.inFlight[id] == rec {
+		delete(r.inFlight, id)
+	}
+}
+
+// SaveRun persists a run to the store
+func (r *Recorder) SaveRun(run ark.Run) error {
+	return r.store.SaveRun(run)
+}
+
+// GetRuns returns the runs of the store with the runs that are being recorded in their current state
+func (r *Recorder) GetRuns() ([]ark.Run, error) {
+	runs, err := r.store.GetRuns()
+	if err != nil {
+		return runs, err
+	}
+
+	recorded := r.recorded()
+	for i, run := range runs {
+		if current, ok := recorded[run.ID]; ok {
+			runs[i] = current
+			delete(recorded, run.ID)
+		}
+	}
+	for _, run := range recorded {
+		runs = append(runs, run)
+	}
+
+	ark.SortRuns(runs)
+	return runs, nil
+}
+
+// GetRunByID returns the current state of a run that is being recorded or the run from the store
+func (r *Recorder) GetRunByID(id string) (ark.Run, error) {
+	r.mutex.Lock()
+	rec, ok := r.inFlight[id]
+	r.mutex.Unlock()
+	if !ok {
+		return r.store.GetRunByID(id)
+	}
+
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	return snapshot(rec.run), nil
+}
+
+// recorded returns a snapshot of every run that is being recorded
+func (r *Recorder) recorded() map[string]ark.Run {
+	r.mutex.Lock()
+	recordings := make([]*recording, 0, len(r.inFlight))
+	for _, rec := range r.inFlight {
+		recordings = append(recordings, rec)
+	}
+	r.mutex.Unlock()
+
+	runs := make(map[string]ark.Run, len(recordings))
+	for _, rec := range recordings {
+		rec.mutex.Lock()
+		runs[rec.run.ID] = snapshot(rec.run)
+		rec.mutex.Unlock()
+	}
+	return runs
+}
+
+// snapshot copies a run so it is not modified by the events applied to the recording afterwards
+func snapshot(run ark.Run) ark.Run {
+	targets := make(ark.RunTargets, len(run.Targets))
+	copy(targets, run.Targets)
+	run.Targets = targets
+	return run
+}
+
+// Store wraps a store so the runs it returns include the runs being recorded in their current state
+func (r *Recorder) Store(store ark.Store) ark.Store {
+	return recordingStore{Store: store, recorder: r}
+}
+
+type recordingStore struct {
+	ark.Store
+	recorder *Recorder
+}
+
+func (s recordingStore) SaveRun(run ark.Run) error {
+	return s.recorder.SaveRun(run)
+}
+
+func (s recordingStore) GetRuns() ([]ark.Run, error) {
+	return s.recorder.GetRuns()
+}
+
+func (s recordingStore) GetRunByID(id string) (ark.Run, error) {
+	return s.recorder.GetRunByID(id)
+}
+
+// Apply updates a run with the contents of a graph runner or graph walker event
+func Apply(run *ark.Run, msg cqrs.Envelope) error {
+	at := msg.Time()
+	earliest(&run.StartedAt, at)
+
+	switch msg.TypeKey() {
+	case events.GraphRunnerStarted:
+		if len(msg.Data()) == 0 {
+			return nil
+		}
+		cmd := new(messages.GraphRunnerExecuteCommand)
+		if err := msg.DataAs(cmd); err != nil {
+			return errors.Wrap(err, "failed to unmarshal the graph runner command")
+		}
+		run.TargetKeys = cmd.TargetKeys
+		run.MaxConcurrency = cmd.MaxConcurrency
+		return nil
+	case events.GraphRunnerSuccess:
+		run.Status = ark.RunStatusSuccess
+		run.FinishedAt = at
+		return nil
+	case events.GraphRunnerFailed:
+		run.Status = ark.RunStatusFailed
+		run.Error = string(msg.Data())
+		run.FinishedAt = at
+		return nil
+	case events.GraphWalkerStarted:
+		return nil
+	}
+
+	var d ark.Derivative
+	if err := msg.DataAs(&d); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal the derivation of %s", msg.Type())
+	}
+
+	target := run.Target(d.RawTarget.Key())
+	target.Hash = d.RawArtifact.Hash
+	target.Type = d.RawTarget.Type
+
+	// the walker records when it began processing the derivation and how long it took
+	// which is more accurate than the time the events were published
+	earliest(&target.StartedAt, d.StartedAt)
+	finishedAt := at
+	if d.Duration > 0 && !d.StartedAt.IsZero() {
+		finishedAt = d.StartedAt.Add(d.Duration)
+	}
+
+	switch msg.TypeKey() {
+	case events.GraphWalkerDerivationComputed:
+		earliest(&target.QueuedAt, at)
+	case events.GraphWalkerActionCached:
+		if advance(target, ark.RunStatusCached) {
+			target.FinishedAt = finishedAt
+		}
+	case events.GraphWalkerActionSkipped:
+		if advance(target, ark.RunStatusSkipped) {
+			target.FinishedAt = finishedAt
+		}
+	case events.GraphWalkerActionStarted:
+		earliest(&target.StartedAt, at)
+		advance(target, ark.RunStatusRunning)
+	case events.GraphWalkerActionRetry:
+		// the error of a retried attempt is kept so a flaky target can be diagnosed after it succeeds
+		target.Attempts = d.Attempt
+		target.Error = d.Error
+	case events.GraphWalkerActionSuccess:
+		if advance(target, ark.RunStatusSuccess) {
+			target.Attempts = d.Attempt
+			target.FinishedAt = finishedAt
+		}
+	case events.GraphWalkerActionFailureAllowed:
+		if advance(target, ark.RunStatusFailureAllowed) {
+			target.Attempts = d.Attempt
+			target.Error = d.Error
+			target.FinishedAt = finishedAt
+		}
+	case events.GraphWalkerActionBlocked:
+		if advance(target, ark.RunStatusBlocked) {
+			target.FinishedAt = at
+		}
+	case events.GraphWalkerFailed:
+		if advance(target, ark.RunStatusFailed) {
+			if d.Attempt > 0 {
+				target.Attempts = d.Attempt
+			}
+			target.Error = d.Error
+			target.FinishedAt = finishedAt
+		}
+	}
+	return nil
+}
+
+// advance moves a target to the next status unless it has already reached a later one
+func advance(target *ark.RunTarget, status ark.RunStatus) bool {
+	if statusOrder(status) <= statusOrder(target.Status) {
+		return false
+	}
+	target.Status = status
+	return true
+}
+
+func statusOrder(status ark.RunStatus) int {
+	switch {
+	case status.Done():
+		return 2
+	case status == ark.RunStatusRunning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func earliest(current *time.Time, at time.Time) {
+	if at.IsZero() {
+		return
+	}
+	if current.IsZero() || at.Before(*current) {
+		*current = at
+	}
+}
@@ -0,0 +1,141 @@
+package run_recorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/events"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/messages"
+	"github.com/myfintech/ark/src/go/lib/ark/storage/memory"
+)
+
+func TestRecorder(t *testing.T) {
+	store := new(memory.Store)
+	recorder := NewRecorder(store)
+	start := time.Now()
+
+	build := ark.Derivative{RawTarget: ark.RawTarget{Name: "build", File: "/repo/build.ts", Realm: "/repo", Type: "group"}}
+	test := ark.Derivative{RawTarget: ark.RawTarget{Name: "test", File: "/repo/build.ts", Realm: "/repo", Type: "group"}}
+	test.Error = "exit status 1"
+
+	envelope := func(eventType cqrs.RouteKey, offset time.Duration, data interface{}) cqrs.Envelope {
+		options := []cqrs.EnvelopeOption{
+			cqrs.WithSource("test"),
+			cqrs.WithType(eventType),
+			cqrs.WithSubject("run-1"),
+			cqrs.WithTime(start.Add(offset)),
+		}
+		if data != nil {
+			options = append(options, cqrs.WithData(cqrs.ApplicationJSON, data))
+		}
+		return cqrs.NewDefaultEnvelope(options...)
+	}
+
+	// events are intentionally applied out of order
+	for _, msg := range []cqrs.Envelope{
+		envelope(events.GraphWalkerActionSuccess, 3*time.Second, build),
+		envelope(events.GraphRunnerStarted, 0, messages.GraphRunnerExecuteCommand{TargetKeys: []string{"build.ts:build"}}),
+		envelope(events.GraphWalkerDerivationComputed, time.Second, build),
+		envelope(events.GraphWalkerActionStarted, time.Second, build),
+		envelope(events.GraphWalkerActionCached, 2*time.Second, ark.Derivative{RawTarget: test.RawTarget}),
+		envelope(events.GraphWalkerFailed, 4*time.Second, test),
+		envelope(events.GraphRunnerFailed, 5*time.Second, nil),
+	} {
+		require.NoError(t, recorder.Record(msg))
+	}
+
+	run, err := store.GetRunByID("run-1")
+	require.NoError(t, err)
+	require.Equal(t, ark.RunStatusFailed, run.Status)
+	require.Equal(t, []string{"build.ts:build"}, []string(run.TargetKeys))
+	require.Equal(t, 5*time.Second, run.Duration())
+	require.Len(t, run.Targets, 2)
+
+	require.Equal(t, ark.RunStatusSuccess, run.Target(build.RawTarget.Key()).Status)
+	require.Equal(t, 2*time.Second, run.Target(build.RawTarget.Key()).Duration())
+
+	// a target reaches a single terminal status
+	require.True(t, run.Target(test.RawTarget.Key()).Cached())
+	require.Empty(t, run.Target(test.RawTarget.Key()).Error)
+}
+
+func TestRecorder_retries(t *testing.T) {
+	run := ark.Run{ID: "run-2"}
+	flaky := ark.Derivative{RawTarget: ark.RawTarget{Name: "flaky", File: "/repo/build.ts", Realm: "/repo", Type: "group"}}
+	allowed := ark.Derivative{RawTarget: ark.RawTarget{Name: "allowed", File: "/repo/build.ts", Realm: "/repo", Type: "group"}}
+
+	envelope := func(eventType cqrs.RouteKey, data ark.Derivative, attempt int, err string) cqrs.Envelope {
+		data.Attempt = attempt
+		data.Error = err
+		return cqrs.NewDefaultEnvelope(
+			cqrs.WithSource("test"),
+			cqrs.WithType(eventType),
+			cqrs.WithSubject("run-2"),
+			cqrs.WithData(cqrs.ApplicationJSON, data),
+		)
+	}
+
+	for _, msg := range []cqrs.Envelope{
+		envelope(events.GraphWalkerActionStarted, flaky, 0, ""),
+		envelope(events.GraphWalkerActionRetry, flaky, 1, "connection reset"),
+		envelope(events.GraphWalkerActionSuccess, flaky, 2, ""),
+		envelope(events.GraphWalkerActionRetry, allowed, 1, "exit status 1"),
+		envelope(events.GraphWalkerActionFailureAllowed, allowed, 2, "exit status 1"),
+	} {
+		require.NoError(t, Apply(&run, msg))
+	}
+
+	require.True(t, run.Target(flaky.RawTarget.Key()).Flaky())
+	require.Equal(t, 2, run.Target(flaky.RawTarget.Key()).Attempts)
+	require.Equal(t, "connection reset", run.Target(flaky.RawTarget.Key()).Error)
+
+	require.Equal(t, ark.RunStatusFailureAllowed, run.Target(allowed.RawTarget.Key()).Status)
+	require.True(t, run.Target(allowed.RawTarget.Key()).Status.Done())
+	require.Equal(t, "exit status 1", run.Target(allowed.RawTarget.Key()).Error)
+}
+
+func TestRecorder_inFlight(t *testing.T) {
+	store := new(memory.Store)
+	recorder := NewRecorder(store)
+	build := ark.Derivative{RawTarget: ark.RawTarget{Name: "build", File: "/repo/build.ts", Realm: "/repo", Type: "group"}}
+
+	envelope := func(eventType cqrs.RouteKey, data interface{}) cqrs.Envelope {
+		options := []cqrs.EnvelopeOption{
+			cqrs.WithSource("test"),
+			cqrs.WithType(eventType),
+			cqrs.WithSubject("run-3"),
+		}
+		if data != nil {
+			options = append(options, cqrs.WithData(cqrs.ApplicationJSON, data))
+		}
+		return cqrs.NewDefaultEnvelope(options...)
+	}
+
+	require.NoError(t, recorder.Record(envelope(events.GraphRunnerStarted, messages.GraphRunnerExecuteCommand{})))
+	require.NoError(t, recorder.Record(envelope(events.GraphWalkerActionStarted, build)))
+
+	persisted, err := store.GetRunByID("run-3")
+	require.NoError(t, err)
+	require.Empty(t, persisted.Targets, "target events are not persisted while the run executes")
+
+	current, err := recorder.Store(store).GetRunByID("run-3")
+	require.NoError(t, err)
+	require.Equal(t, ark.RunStatusRunning, current.Target(build.RawTarget.Key()).Status)
+
+	runs, err := recorder.GetRuns()
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Len(t, runs[0].Targets, 1)
+
+	require.NoError(t, recorder.Record(envelope(events.GraphWalkerActionSuccess, build)))
+	require.NoError(t, recorder.Record(envelope(events.GraphRunnerSuccess, nil)))
+
+	persisted, err = store.GetRunByID("run-3")
+	require.NoError(t, err)
+	require.Equal(t, ark.RunStatusSuccess, persisted.Status)
+	require.Equal(t, ark.RunStatusSuccess, persisted.Target(build.RawTarget.Key()).Status)
+}
@@ -59,6 +59,9 @@ type RawTarget struct {
 	Attributes               json_datatypes.MapStringInterface `json:"attributes" mapstructure:"attributes,remain"`
 	SourceFiles              json_datatypes.StringSlice        `json:"sourceFiles" mapstructure:"sourceFiles" hash:"-"`
 	Labels                   json_datatypes.StringSlice        `json:"labels" mapstructure:"labels" hash:"-"`
+	Outputs                  json_datatypes.StringSlice        `json:"outputs" mapstructure:"outputs" hash:"-"`
+	Policy                   ExecutionPolicy                   `json:"policy" mapstructure:"policy" hash:"-"`
+	Resources                json_datatypes.MapStringInt64     `json:"resources" mapstructure:"resources" hash:"-"`
 	DependsOn                Ancestors                         `json:"dependsOn" mapstructure:"dependsOn" hash:"-"`
 	ExcludeFromHash          ExcludeFromHash                   `json:"excludeFromHash" mapstructure:"excludeFromHash" hash:"-"`
 	IgnoreFileNotExistsError bool                              `json:"ignoreFileNotExistsError" mapstructure:"ignoreFileNotExistsError"`
@@ -119,36 +122,68 @@ func (t *RawTarget) Validate() error {
 	if err := t.validateAndNormalizeSourceFiles(); err != nil {
 		return err
 	}
+	if err := t.validateOutputs(); err != nil {
+		return err
+	}
+	if err := t.validateResources(); err != nil {
+		return err
+	}
 	return validation.ValidateStruct(t,
 		validation.Field(&t.Name, validation.Required),
 		validation.Field(&t.Type, validation.Required),
 		validation.Field(&t.File, validation.Required),
 		validation.Field(&t.Realm, validation.Required),
+		validation.Field(&t.Policy),
 	)
 }
 
+// validateResources ensures every resource pool has a name and a positive weight
+func (t RawTarget) validateResources() error {
+	for pool, weight := range t.Resources {
+		if pool == "" {
+			return errors.Errorf("the resources of target %s cannot contain an empty pool name", t.Key())
+		}
+		if weight < 1 {
+			return errors.Errorf("the weight of resource pool %s of target %s must be positive", pool, t.Key())
+		}
+	}
+	return nil
+}
+
 // Checksum executes the hash calculation algorithm on the provided target
 func (t *RawTarget) Checksum() (rootHash hash.Hash, err error) {
+	rootHash, _, err = t.ChecksumInputs()
+	return
+}
+
+// ChecksumInputs executes the hash calculation algorithm on the provided target
+// and returns the inputs that were mixed into the hash so they can be recorded with the artifact
+func (t *RawTarget) ChecksumInputs() (rootHash hash.Hash, inputs HashInputs, err error) {
 	rootHash = sha256.New()
+	inputs = newHashInputs()
 
-	if err = t.hashAttributes(rootHash); err != nil {
+	if err = t.hashAttributes(rootHash, &inputs); err != nil {
 		return
 	}
 
 	// TrimPrefixAll returns a sorted list of files
 	// add the hash of each file to the root hash
-	if err = t.hashSourceFiles(rootHash); err != nil {
+	if err = t.hashSourceFiles(rootHash, &inputs); err != nil {
 		return
 	}
 
-	if err = t.hashAncestors(rootHash); err != nil {
+	if err = t.hashAncestors(rootHash, &inputs); err != nil {
 		return
 	}
 
-	return rootHash, nil
+	if err = t.hashOutputs(rootHash, &inputs); err != nil {
+		return
+	}
+
+	return rootHash, inputs, nil
 }
 
-func (t *RawTarget) hashAttributes(rootHash hash.Hash) error {
+func (t *RawTarget) hashAttributes(rootHash hash.Hash, inputs *HashInputs) error {
 	structHash, err := hashstructure.Hash(t, hashstructure.FormatV2, nil)
 	if err != nil {
 		return err
@@ -158,10 +193,10 @@ func (t *RawTarget) hashAttributes(rootHash hash.Hash) error {
 	if err != nil {
 		return err
 	}
-	return nil
+	return t.recordAttributes(inputs)
 }
 
-func (t *RawTarget) hashAncestors(rootHash hash.Hash) error {
+func (t *RawTarget) hashAncestors(rootHash hash.Hash, inputs *HashInputs) error {
 	// sort ancestors
 	sort.Slice(t.DependsOn, func(i, j int) bool {
 		return t.DependsOn[i].Hash > t.DependsOn[j].Hash
@@ -172,11 +207,12 @@ func (t *RawTarget) hashAncestors(rootHash hash.Hash) error {
 		if _, err := fmt.Fprintf(rootHash, "%s\n", ancestor.Hash); err != nil {
 			return err
 		}
+		inputs.Ancestors[ancestor.Key] = ancestor.Hash
 	}
 	return nil
 }
 
-func (t *RawTarget) hashSourceFiles(rootHash hash.Hash) error {
+func (t *RawTarget) hashSourceFiles(rootHash hash.Hash, inputs *HashInputs) error {
 	for _, file := range fs.TrimPrefixAll(t.SourceFiles, t.Realm) {
 		filename := filepath.Join(t.Realm, file)
 		stat, err := os.Stat(filename)
@@ -201,6 +237,7 @@ func (t *RawTarget) hashSourceFiles(rootHash hash.Hash) error {
 		if _, err = fmt.Fprintf(rootHash, "%s:%x\n", file, fileHash.Sum(nil)); err != nil {
 			return err
 		}
+		inputs.SourceFiles[file] = hex.EncodeToString(fileHash.Sum(nil))
 	}
 	return nil
 }
@@ -0,0 +1,230 @@
+package ark
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultBuildFileName the name of the build files discovered by directory selectors
+const DefaultBuildFileName = "build.ts"
+
+// recursiveSelectorSuffix selects every build file below a directory
+const recursiveSelectorSuffix = "..."
+
+// TargetSelector selects targets by the location of their build file and their name
+//
+//	//src/go/services/api/build.ts:image  the image target of a build file relative to the realm
+//	//src/go/services/api:image           the image target of the build file in a directory
+//	//src/go/services/...:image           the image target of every build file below a directory
+//	//src/go/services/...                 every target below a directory
+//	src/go/services/api/build.ts:image    a path relative to the current working directory
+//
+// The name may be a glob pattern (e.g. image-*)
+type TargetSelector struct {
+	// Path the absolute path of a build file or directory
+	Path string
+
+	// Recursive selects the build files in every directory below Path
+	Recursive bool
+
+	// Name a glob pattern that is matched against the name of a target
+	Name string
+}
+
+// ParseTargetSelector parses a selector, paths starting with // are relative to realm and other relative paths to cwd
+func ParseTargetSelector(selector, realm, cwd string) (TargetSelector, error) {
+	s := TargetSelector{Name: "*"}
+
+	location := selector
+	if idx := strings.LastIndex(selector, ":"); idx != -1 {
+		location, s.Name = selector[:idx], selector[idx+1:]
+	}
+
+	if s.Name == "" {
+		return s, errors.Errorf("target selector %s must specify a target name after the colon", selector)
+	}
+	if _, err := path.Match(s.Name, ""); err != nil {
+		return s, errors.Wrapf(err, "target selector %s has an invalid name pattern", selector)
+	}
+
+	if strings.HasSuffix(location, recursiveSelectorSuffix) {
+		s.Recursive = true
+		location = strings.TrimSuffix(location, recursiveSelectorSuffix)
+	}
+
+	switch {
+	case strings.HasPrefix(location, "//"):
+		s.Path = filepath.Join(realm, strings.TrimPrefix(location, "//"))
+	case filepath.IsAbs(location):
+		s.Path = filepath.Clean(location)
+	default:
+		s.Path = filepath.Join(cwd, location)
+	}
+
+	if !s.Recursive && s.Name == "*" && !strings.Contains(selector, ":") {
+		return s, errors.Errorf("target selector %s must specify a target name or end with %s", selector, recursiveSelectorSuffix)
+	}
+	return s, nil
+}
+
+// Match returns true if the target is selected
+func (s TargetSelector) Match(target RawTarget) bool {
+	if matched, _ := path.Match(s.Name, target.Name); !matched {
+		return false
+	}
+
+	file := filepath.Clean(target.File)
+	if s.Recursive {
+		return strings.HasPrefix(file, s.Path+string(os.PathSeparator))
+	}
+	return file == s.Path || filepath.Dir(file) == s.Path
+}
+
+// BuildFiles returns the build files that must be loaded to evaluate the targets the selector may match
+func (s TargetSelector) BuildFiles(buildFileName string) ([]string, error) {
+	if !s.Recursive {
+		info, err := os.Stat(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return []string{s.Path}, nil
+		}
+		buildFile := filepath.Join(s.Path, buildFileName)
+		if _, err = os.Stat(buildFile); err != nil {
+			return nil, err
+		}
+		return []string{buildFile}, nil
+	}
+
+	var buildFiles []string
+	err := filepath.Walk(s.Path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if file != s.Path && (strings.HasPrefix(name, ".") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() == buildFileName {
+			buildFiles = append(buildFiles, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(buildFiles) == 0 {
+		return nil, errors.Errorf("no %s files were found below %s", buildFileName, s.Path)
+	}
+	return buildFiles, nil
+}
+
+// SelectTargets returns the sorted keys of the targets matched by any of the selectors
+// An error is returned if a selector does not match any target
+func SelectTargets(targets []RawTarget, selectors []TargetSelector) ([]string, error) {
+	selected := make(map[string]bool)
+	for _, selector := range selectors {
+		matched := false
+		for _, target := range targets {
+			if selector.Match(target) {
+				selected[target.Key()] = true
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, errors.Errorf("no targets matched the selector %s:%s", selector.Path, selector.Name)
+		}
+	}
+
+	keys := make([]string, 0, len(selected))
+	for key := range selected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// LabelSelector selects targets by their labels
+// A label selector of key=value matches a target with that exact label
+// A label selector of key matches a target with the label key or any label key=value
+type LabelSelector struct {
+	// Include every label selector must match a target for it to be selected
+	Include []string `json:"include,omitempty"`
+
+	// Exclude a target is not selected if any of these label selectors match
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// NewLabelSelector creates a label selector, empty selectors are ignored
+func NewLabelSelector(include, exclude []string) LabelSelector {
+	return LabelSelector{
+		Include: nonEmpty(include),
+		Exclude: nonEmpty(exclude),
+	}
+}
+
+// Empty returns true if the selector matches every target
+func (s LabelSelector) Empty() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0
+}
+
+// Match returns true if the labels of the target satisfy the selector
+func (s LabelSelector) Match(target RawTarget) bool {
+	for _, selector := range s.Include {
+		if !hasLabel(target.Labels, selector) {
+			return false
+		}
+	}
+	for _, selector := range s.Exclude {
+		if hasLabel(target.Labels, selector) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the targets that satisfy the selector
+func (s LabelSelector) Filter(targets []RawTarget) []RawTarget {
+	if s.Empty() {
+		return targets
+	}
+	var filtered []RawTarget
+	for _, target := range targets {
+		if s.Match(target) {
+			filtered = append(filtered, target)
+		}
+	}
+	return filtered
+}
+
+func hasLabel(labels []string, selector string) bool {
+	for _, label := range labels {
+		if label == selector {
+			return true
+		}
+		if !strings.Contains(selector, "=") && strings.HasPrefix(label, selector+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
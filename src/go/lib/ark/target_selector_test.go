@@ -0,0 +1,115 @@
+package ark
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetSelector(t *testing.T) {
+	realm := t.TempDir()
+	for _, dir := range []string{"services/api", "services/worker", "services/node_modules/dep", "libs/utils"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(realm, dir), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(realm, dir, DefaultBuildFileName), []byte(""), 0644))
+	}
+
+	newTarget := func(dir, name string) RawTarget {
+		return RawTarget{
+			Name:  name,
+			Realm: realm,
+			File:  filepath.Join(realm, dir, DefaultBuildFileName),
+		}
+	}
+	targets := []RawTarget{
+		newTarget("services/api", "image"),
+		newTarget("services/api", "test"),
+		newTarget("services/worker", "image"),
+		newTarget("libs/utils", "image"),
+	}
+
+	t.Run("should parse realm, relative and recursive selectors", func(t *testing.T) {
+		s, err := ParseTargetSelector("//services/api/build.ts:image", realm, "/tmp")
+		require.NoError(t, err)
+		require.Equal(t, TargetSelector{Path: filepath.Join(realm, "services/api/build.ts"), Name: "image"}, s)
+
+		s, err = ParseTargetSelector("api:image", realm, filepath.Join(realm, "services"))
+		require.NoError(t, err)
+		require.Equal(t, TargetSelector{Path: filepath.Join(realm, "services/api"), Name: "image"}, s)
+
+		s, err = ParseTargetSelector("//services/...", realm, "/tmp")
+		require.NoError(t, err)
+		require.Equal(t, TargetSelector{Path: filepath.Join(realm, "services"), Recursive: true, Name: "*"}, s)
+
+		_, err = ParseTargetSelector("//services/api", realm, "/tmp")
+		require.Error(t, err)
+
+		_, err = ParseTargetSelector("//services/api:[", realm, "/tmp")
+		require.Error(t, err)
+	})
+
+	t.Run("should select targets in a single merged set of keys", func(t *testing.T) {
+		recursive, err := ParseTargetSelector("//services/...:image", realm, realm)
+		require.NoError(t, err)
+		directory, err := ParseTargetSelector("//libs/utils:*", realm, realm)
+		require.NoError(t, err)
+		file, err := ParseTargetSelector("//services/api/build.ts:image", realm, realm)
+		require.NoError(t, err)
+
+		keys, err := SelectTargets(targets, []TargetSelector{recursive, directory, file})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"libs/utils/build.ts:image",
+			"services/api/build.ts:image",
+			"services/worker/build.ts:image",
+		}, keys)
+
+		missing, err := ParseTargetSelector("//services/...:missing", realm, realm)
+		require.NoError(t, err)
+		_, err = SelectTargets(targets, []TargetSelector{missing})
+		require.Error(t, err)
+	})
+
+	t.Run("should find build files and skip node_modules", func(t *testing.T) {
+		s, err := ParseTargetSelector("//services/...", realm, realm)
+		require.NoError(t, err)
+
+		buildFiles, err := s.BuildFiles(DefaultBuildFileName)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			filepath.Join(realm, "services/api", DefaultBuildFileName),
+			filepath.Join(realm, "services/worker", DefaultBuildFileName),
+		}, buildFiles)
+
+		s, err = ParseTargetSelector("//libs/utils:image", realm, realm)
+		require.NoError(t, err)
+		buildFiles, err = s.BuildFiles(DefaultBuildFileName)
+		require.NoError(t, err)
+		require.Equal(t, []string{filepath.Join(realm, "libs/utils", DefaultBuildFileName)}, buildFiles)
+	})
+}
+
+func TestLabelSelector(t *testing.T) {
+	targets := []RawTarget{
+		{Name: "api", Labels: []string{"team=payments", "image"}},
+		{Name: "api-test", Labels: []string{"team=payments", "test=integration", "slow"}},
+		{Name: "worker", Labels: []string{"team=ledger", "image"}},
+	}
+
+	names := func(targets []RawTarget) []string {
+		var result []string
+		for _, target := range targets {
+			result = append(result, target.Name)
+		}
+		return result
+	}
+
+	require.True(t, NewLabelSelector(nil, []string{""}).Empty())
+	require.Equal(t, []string{"api", "api-test", "worker"}, names(NewLabelSelector(nil, nil).Filter(targets)))
+	require.Equal(t, []string{"api", "api-test"}, names(NewLabelSelector([]string{"team=payments"}, nil).Filter(targets)))
+	require.Equal(t, []string{"api"}, names(NewLabelSelector([]string{"team"}, []string{"slow"}).Filter(targets)))
+	require.Equal(t, []string{"api-test"}, names(NewLabelSelector([]string{"test"}, nil).Filter(targets)))
+	require.Equal(t, []string{"worker"}, names(NewLabelSelector([]string{"image"}, []string{"team=payments"}).Filter(targets)))
+	require.Empty(t, NewLabelSelector([]string{"team=infra"}, nil).Filter(targets))
+}
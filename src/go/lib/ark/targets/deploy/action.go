@@ -360,7 +360,12 @@ func applyArkMutationsToManifest(fileName string, target *Target) error {
 		_ = dest.Close()
 	}()
 
-	deserializedManifest, err := deserializeManifestString(target.Manifest)
+	renderedManifest, err := target.RenderManifest()
+	if err != nil {
+		return err
+	}
+
+	deserializedManifest, err := deserializeManifestString(renderedManifest)
 	if err != nil {
 		return err
 	}
@@ -3,8 +3,12 @@ package deploy
 import (
 	"encoding/hex"
 	"hash"
+	"os"
+	"path/filepath"
+	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
 
 	"github.com/myfintech/ark/src/go/lib/ark"
 	"github.com/myfintech/ark/src/go/lib/kube/portbinder"
@@ -24,6 +28,7 @@ type Step struct {
 type Target struct {
 	ark.RawTarget       `mapstructure:",squash"`
 	Manifest            string              `mapstructure:"manifest"            json:"manifest"`
+	ManifestFiles       []string            `mapstructure:"manifestFiles"       json:"manifestFiles"`
 	PortForward         portbinder.PortMap  `mapstructure:"portForward"         json:"portForward"` // TODO: There's no input validation for this in this code
 	LiveSyncEnabled     bool                `mapstructure:"liveSyncEnabled"     json:"liveSyncEnabled"`
 	LiveSyncRestartMode string              `mapstructure:"liveSyncRestartMode" json:"liveSyncRestartMode"` // TODO: There's no input validation for this code or setting of a default
@@ -51,6 +56,29 @@ func (t *Target) Validate() error {
 		return err
 	}
 	return validation.ValidateStruct(t,
-		validation.Field(&t.Manifest, validation.Required),
+		validation.Field(&t.Manifest, validation.When(len(t.ManifestFiles) == 0, validation.Required)),
 	)
 }
+
+// RenderManifest returns the manifest combined with the contents of each manifest file as a single YAML stream
+// Relative manifest files are resolved from the build file directory
+func (t Target) RenderManifest() (string, error) {
+	var documents []string
+	if t.Manifest != "" {
+		documents = append(documents, t.Manifest)
+	}
+
+	for _, file := range t.ManifestFiles {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(t.Dir(), file)
+		}
+
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read manifest file")
+		}
+		documents = append(documents, string(contents))
+	}
+
+	return strings.Join(documents, "\n---\n"), nil
+}
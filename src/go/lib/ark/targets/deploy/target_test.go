@@ -34,3 +34,34 @@ func TestTarget(t *testing.T) {
 	err = target.Validate()
 	require.NoError(t, err)
 }
+
+func TestTarget_RenderManifest(t *testing.T) {
+	realm := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(realm, "service.json"), []byte(`{"kind": "Service"}`), 0644))
+
+	target := Target{
+		Manifest:      "kind: ConfigMap",
+		ManifestFiles: []string{"service.json"},
+		RawTarget: ark.RawTarget{
+			Name:  "deploy_test",
+			Type:  Type,
+			File:  filepath.Join(realm, "build.ts"),
+			Realm: realm,
+		},
+	}
+	require.NoError(t, target.Validate())
+
+	manifest, err := target.RenderManifest()
+	require.NoError(t, err)
+	require.Equal(t, "kind: ConfigMap\n---\n{\"kind\": \"Service\"}", manifest)
+
+	target.Manifest = ""
+	require.NoError(t, target.Validate())
+
+	target.ManifestFiles = []string{"missing.yaml"}
+	_, err = target.RenderManifest()
+	require.Error(t, err)
+
+	target.ManifestFiles = nil
+	require.Error(t, target.Validate())
+}
@@ -0,0 +1,167 @@
+package http_archive
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/fs"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+// Action is the executor for downloading and verifying an http archive
+type Action struct {
+	Artifact *Artifact
+	Target   *Target
+	Logger   logz.FieldLogger
+}
+
+var _ logz.Injector = &Action{}
+
+// UseLogger injects a logger into the target's action
+func (a *Action) UseLogger(logger logz.FieldLogger) {
+	a.Logger = logger
+}
+
+// Execute downloads the file, verifies its checksum and optionally extracts it into the artifact
+func (a Action) Execute(_ context.Context) error {
+	// staging directories are siblings of the contents so they can be renamed into place
+	downloadDir := a.Artifact.ContentsPath + ".download"
+	extractDir := a.Artifact.ContentsPath + ".extract"
+	defer func() {
+		_ = os.RemoveAll(downloadDir)
+		_ = os.RemoveAll(extractDir)
+	}()
+
+	for _, dir := range []string{downloadDir, extractDir, a.Artifact.ContentsPath} {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+
+	if a.Logger != nil {
+		a.Logger.Infof("downloading %s", a.Target.URL)
+	}
+
+	downloaded, err := fs.Download(a.Target.URL, downloadDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	if _, err = fs.CompareFileHash(downloaded, a.Target.Sha256); err != nil {
+		return errors.Wrapf(err, "%s failed checksum verification", a.Target.URL)
+	}
+
+	if !a.Target.Decompress {
+		if err = os.MkdirAll(a.Artifact.ContentsPath, 0755); err != nil {
+			return err
+		}
+		return os.Rename(downloaded, filepath.Join(a.Artifact.ContentsPath, filepath.Base(downloaded)))
+	}
+
+	format, err := a.Target.ArchiveFormat()
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatZip:
+		err = unzip(downloaded, extractDir)
+	default:
+		err = untar(downloaded, extractDir)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to extract %s", a.Target.URL)
+	}
+
+	// the cleaned root must stay inside of the extraction directory so a prefix can't move files from elsewhere into the artifact
+	root := filepath.Join(extractDir, a.Target.StripPrefix)
+	if root != filepath.Clean(extractDir) && !strings.HasPrefix(root, filepath.Clean(extractDir)+string(os.PathSeparator)) {
+		return errors.Errorf("the strip prefix %s is outside of the archive", a.Target.StripPrefix)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return errors.Wrapf(err, "the archive does not contain the strip prefix %s", a.Target.StripPrefix)
+	}
+	if !info.IsDir() {
+		return errors.Errorf("the strip prefix %s is not a directory in the archive", a.Target.StripPrefix)
+	}
+
+	return os.Rename(root, a.Artifact.ContentsPath)
+}
+
+func untar(archive, dest string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	if err = os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	return fs.GzipUntar(dest, file)
+}
+
+func unzip(archive, dest string) error {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	for _, file := range reader.File {
+		path := filepath.Join(dest, file.Name)
+
+		// prevents files from being written outside of the extraction directory
+		if !strings.HasPrefix(path, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return errors.Errorf("file: '%s' contains a relative path that could be exploited", file.Name)
+		}
+
+		if file.FileInfo().IsDir() {
+			if err = os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = extractZipFile(file, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(file *zip.File, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+
+	dest, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, file.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = dest.Close()
+	}()
+
+	_, err = io.Copy(dest, src)
+	return err
+}
@@ -0,0 +1,20 @@
+package http_archive
+
+import (
+	"path/filepath"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+// Artifact the verified download or the extracted contents of an archive
+// The artifact hash is derived from the URL and sha256 of the target so a cached artifact is never downloaded again
+type Artifact struct {
+	ark.RawArtifact `mapstructure:",squash"`
+
+	// ContentsPath the directory in the local artifact cache that holds the downloaded file or the extracted archive
+	ContentsPath string `json:"contentsPath" mapstructure:"contentsPath"`
+}
+
+func contentsPath(cacheDir string) string {
+	return filepath.Join(cacheDir, "contents")
+}
@@ -0,0 +1,191 @@
+package http_archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/fs"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpArchive(t *testing.T) {
+	ctx := context.Background()
+	realm := t.TempDir()
+
+	require.NoError(t, os.Setenv("ARK_CACHE_HOME", t.TempDir()))
+	defer func() {
+		_ = os.Unsetenv("ARK_CACHE_HOME")
+	}()
+
+	srcDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "tool-1.0.0", "bin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "tool-1.0.0", "bin", "tool"), []byte("#!/bin/sh"), 0755))
+
+	tarball := new(bytes.Buffer)
+	require.NoError(t, fs.GzipTar(srcDir, tarball))
+
+	zipball := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(zipball)
+	entry, err := zipWriter.Create("tool/README.md")
+	require.NoError(t, err)
+	_, err = entry.Write([]byte("# tool"))
+	require.NoError(t, err)
+	require.NoError(t, zipWriter.Close())
+
+	files := map[string][]byte{
+		"/tool.txt":          []byte("plain file"),
+		"/tool-1.0.0.tar.gz": tarball.Bytes(),
+		"/tool.zip":          zipball.Bytes(),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contents, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(contents)
+	}))
+	defer server.Close()
+
+	sum := func(contents []byte) string {
+		checksum := sha256.Sum256(contents)
+		return hex.EncodeToString(checksum[:])
+	}
+
+	newTarget := func(name, path, sha string) *Target {
+		return &Target{
+			RawTarget: ark.RawTarget{
+				Name:  name,
+				Type:  Type,
+				File:  filepath.Join(realm, "build.ts"),
+				Realm: realm,
+			},
+			URL:    server.URL + path,
+			Sha256: sha,
+		}
+	}
+
+	execute := func(target *Target) (*Artifact, error) {
+		require.NoError(t, target.Validate())
+
+		checksum, err := target.Checksum()
+		require.NoError(t, err)
+
+		artifact, err := target.Produce(checksum)
+		require.NoError(t, err)
+
+		action := &Action{
+			Target:   target,
+			Artifact: artifact.(*Artifact),
+		}
+		require.Implements(t, (*ark.Action)(nil), action)
+		return action.Artifact, action.Execute(ctx)
+	}
+
+	t.Run("should download and verify a file", func(t *testing.T) {
+		artifact, err := execute(newTarget("plain", "/tool.txt", sum(files["/tool.txt"])))
+		require.NoError(t, err)
+		require.True(t, artifact.Cacheable())
+
+		contents, err := os.ReadFile(filepath.Join(artifact.ContentsPath, "tool.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "plain file", string(contents))
+	})
+
+	t.Run("should extract a tar.gz archive with a strip prefix", func(t *testing.T) {
+		target := newTarget("tarball", "/tool-1.0.0.tar.gz", sum(files["/tool-1.0.0.tar.gz"]))
+		target.Decompress = true
+		target.StripPrefix = "tool-1.0.0"
+
+		artifact, err := execute(target)
+		require.NoError(t, err)
+
+		info, err := os.Stat(filepath.Join(artifact.ContentsPath, "bin", "tool"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	})
+
+	t.Run("should extract a zip archive", func(t *testing.T) {
+		target := newTarget("zipball", "/tool.zip", sum(files["/tool.zip"]))
+		target.Decompress = true
+
+		artifact, err := execute(target)
+		require.NoError(t, err)
+
+		contents, err := os.ReadFile(filepath.Join(artifact.ContentsPath, "tool", "README.md"))
+		require.NoError(t, err)
+		require.Equal(t, "# tool", string(contents))
+	})
+
+	t.Run("should fail if the strip prefix is missing from the archive", func(t *testing.T) {
+		target := newTarget("prefix", "/tool.zip", sum(files["/tool.zip"]))
+		target.Decompress = true
+		target.StripPrefix = "missing"
+
+		_, err := execute(target)
+		require.Error(t, err)
+	})
+
+	t.Run("should fail if the checksum does not match", func(t *testing.T) {
+		artifact, err := execute(newTarget("mismatch", "/tool.txt", sum([]byte("tampered"))))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "checksum")
+
+		_, err = os.Stat(artifact.ContentsPath)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("should fail if the file can't be downloaded", func(t *testing.T) {
+		_, err := execute(newTarget("missing", "/missing.txt", sum(nil)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "404")
+	})
+
+	t.Run("should reject invalid targets", func(t *testing.T) {
+		invalid := newTarget("invalid", "/tool.txt", "not-a-sha")
+		require.Error(t, invalid.Validate())
+
+		invalid = newTarget("invalid", "/tool.txt", sum(nil))
+		invalid.URL = "ftp://example.com/tool.txt"
+		require.Error(t, invalid.Validate())
+
+		invalid = newTarget("invalid", "/tool.txt", sum(nil))
+		invalid.StripPrefix = "tool"
+		require.Error(t, invalid.Validate())
+
+		for _, prefix := range []string{"/etc", "../tool", "tool/../../etc"} {
+			invalid = newTarget("invalid", "/tool.zip", sum(nil))
+			invalid.Decompress = true
+			invalid.StripPrefix = prefix
+			require.Error(t, invalid.Validate(), prefix)
+		}
+	})
+
+	t.Run("should fail if the strip prefix leaves the archive", func(t *testing.T) {
+		target := newTarget("escape", "/tool.zip", sum(files["/tool.zip"]))
+		target.Decompress = true
+		require.NoError(t, target.Validate())
+		target.StripPrefix = "../../"
+
+		checksum, err := target.Checksum()
+		require.NoError(t, err)
+		artifact, err := target.Produce(checksum)
+		require.NoError(t, err)
+
+		err = (&Action{Target: target, Artifact: artifact.(*Artifact)}).Execute(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "outside of the archive")
+	})
+
+}
@@ -0,0 +1,115 @@
+package http_archive
+
+import (
+	"encoding/hex"
+	"hash"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/pkg/errors"
+)
+
+// Type is the string value of the Target type
+const Type = "http_archive"
+
+const (
+	// FormatTarGz a gzipped tar archive
+	FormatTarGz = "tar.gz"
+
+	// FormatZip a zip archive
+	FormatZip = "zip"
+)
+
+var (
+	httpPrefix = regexp.MustCompile("^https?://")
+	sha256Hex  = regexp.MustCompile("^[a-f0-9]{64}$")
+)
+
+// Target expresses the intention to download a file pinned by its sha256 checksum
+type Target struct {
+	ark.RawTarget `mapstructure:",squash"`
+	URL           string `json:"url" mapstructure:"url"`
+	Sha256        string `json:"sha256" mapstructure:"sha256"`
+
+	// Decompress extracts the archive into the artifact instead of storing the downloaded file
+	Decompress bool `json:"decompress" mapstructure:"decompress"`
+
+	// Format the archive format (tar.gz|zip), it is inferred from the URL if empty
+	Format string `json:"format" mapstructure:"format"`
+
+	// StripPrefix a directory inside of the archive whose contents become the root of the artifact
+	StripPrefix string `json:"stripPrefix" mapstructure:"stripPrefix"`
+}
+
+// ArchiveFormat returns the format of the archive
+func (t Target) ArchiveFormat() (string, error) {
+	if t.Format != "" {
+		return t.Format, nil
+	}
+
+	url := strings.ToLower(strings.SplitN(t.URL, "?", 2)[0])
+	switch {
+	case strings.HasSuffix(url, ".tar.gz"), strings.HasSuffix(url, ".tgz"):
+		return FormatTarGz, nil
+	case strings.HasSuffix(url, ".zip"):
+		return FormatZip, nil
+	default:
+		return "", errors.Errorf("the archive format of %s can't be inferred, specify one of %s|%s", t.URL, FormatTarGz, FormatZip)
+	}
+}
+
+// Produce should produce Artifact
+func (t *Target) Produce(checksum hash.Hash) (ark.Artifact, error) {
+	artifact := &Artifact{
+		RawArtifact: ark.RawArtifact{
+			Key:        t.Key(),
+			Type:       t.Type,
+			Hash:       hex.EncodeToString(checksum.Sum(nil)),
+			Attributes: nil,
+		},
+	}
+
+	cacheDir, err := artifact.CacheDirPath()
+	if err != nil {
+		return artifact, err
+	}
+	artifact.ContentsPath = contentsPath(cacheDir)
+
+	return artifact, nil
+}
+
+// Validate checks if the Target fields are valid
+func (t *Target) Validate() error {
+	if err := t.RawTarget.Validate(); err != nil {
+		return err
+	}
+	return validation.ValidateStruct(t,
+		validation.Field(&t.URL, validation.Required, validation.Match(httpPrefix).Error("must be an http(s) URL")),
+		validation.Field(&t.Sha256, validation.Required, validation.Match(sha256Hex).Error("must be a lowercase hex encoded sha256 checksum")),
+		validation.Field(&t.Format, validation.In(FormatTarGz, FormatZip)),
+		validation.Field(&t.StripPrefix,
+			validation.When(!t.Decompress, validation.Empty.Error("requires decompress")),
+			validation.By(validateStripPrefix),
+		),
+	)
+}
+
+// validateStripPrefix rejects strip prefixes that could select a directory outside of the archive
+func validateStripPrefix(value interface{}) error {
+	prefix, _ := value.(string)
+	if prefix == "" {
+		return nil
+	}
+	if filepath.IsAbs(prefix) || strings.HasPrefix(prefix, "/") {
+		return errors.New("must be a relative path")
+	}
+	for _, segment := range strings.Split(filepath.ToSlash(prefix), "/") {
+		if segment == ".." {
+			return errors.New("must not contain .. segments")
+		}
+	}
+	return nil
+}
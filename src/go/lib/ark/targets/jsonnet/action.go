@@ -0,0 +1,85 @@
+package jsonnet
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-jsonnet"
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/jsonnetutils"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+// Action is the executor for rendering jsonnet files
+type Action struct {
+	Artifact *Artifact
+	Target   *Target
+	Logger   logz.FieldLogger
+}
+
+var _ logz.Injector = &Action{}
+
+// UseLogger injects a logger into the target's action
+func (a *Action) UseLogger(logger logz.FieldLogger) {
+	a.Logger = logger
+}
+
+// Execute renders each jsonnet file into the artifact
+func (a Action) Execute(_ context.Context) error {
+	vm := jsonnet.MakeVM()
+	vm.Importer(&jsonnet.FileImporter{
+		JPaths: a.Target.LibraryPaths(),
+	})
+
+	for key, value := range a.Target.ExtVars {
+		vm.ExtVar(key, value)
+	}
+
+	for key, code := range a.Target.ExtCode {
+		vm.ExtCode(key, code)
+	}
+
+	for idx, file := range a.Target.FilePaths() {
+		if err := a.render(vm, file, a.Artifact.RenderedFiles[idx]); err != nil {
+			return errors.Wrapf(err, "failed to render %s", file)
+		}
+	}
+	return nil
+}
+
+func (a Action) render(vm *jsonnet.VM, file, renderedFile string) error {
+	input, err := jsonnetutils.ReadInput(false, file)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(renderedFile), 0755); err != nil {
+		return err
+	}
+
+	dest, err := os.Create(renderedFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = dest.Close()
+	}()
+
+	if a.Target.OutputFormat() == FormatYAML {
+		documents, evalErr := vm.EvaluateSnippetStream(file, input)
+		if evalErr != nil {
+			return evalErr
+		}
+		return jsonnetutils.WriteOutputStream(documents, dest)
+	}
+
+	output, err := vm.EvaluateSnippet(file, input)
+	if err != nil {
+		return err
+	}
+
+	_, err = dest.WriteString(output)
+	return err
+}
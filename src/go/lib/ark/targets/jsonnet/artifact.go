@@ -0,0 +1,18 @@
+package jsonnet
+
+import (
+	"path/filepath"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+// Artifact the result of a successful actions.Jsonnet
+type Artifact struct {
+	ark.RawArtifact `mapstructure:",squash"`
+	RenderedFiles   []string `json:"renderedFiles" mapstructure:"renderedFiles"`
+}
+
+// renderedDir returns the location of the rendered files inside of the artifact cache directory
+func renderedDir(cacheDir string) string {
+	return filepath.Join(cacheDir, "rendered")
+}
@@ -0,0 +1,132 @@
+package jsonnet
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJsonnet(t *testing.T) {
+	ctx := context.Background()
+	realm := t.TempDir()
+
+	require.NoError(t, os.Setenv("ARK_CACHE_HOME", t.TempDir()))
+	defer func() {
+		_ = os.Unsetenv("ARK_CACHE_HOME")
+	}()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(realm, "lib"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(realm, "lib", "service.libsonnet"), []byte(`{
+  service(name, replicas):: { kind: "Deployment", name: name, replicas: replicas },
+}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(realm, "app.jsonnet"), []byte(`local lib = import "service.libsonnet";
+lib.service(std.extVar("name"), std.extVar("replicas"))`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(realm, "stream.jsonnet"), []byte(`[{ kind: "ConfigMap" }, { kind: "Service" }]`), 0644))
+
+	newTarget := func(files ...string) *Target {
+		return &Target{
+			RawTarget: ark.RawTarget{
+				Name:  "manifests",
+				Type:  Type,
+				File:  filepath.Join(realm, "build.ts"),
+				Realm: realm,
+			},
+			Files:       files,
+			LibraryDirs: []string{"lib"},
+			ExtVars:     map[string]string{"name": "api"},
+			ExtCode:     map[string]string{"replicas": "1 + 2"},
+		}
+	}
+
+	execute := func(target *Target) (*Artifact, error) {
+		require.NoError(t, target.Validate())
+
+		checksum, err := target.Checksum()
+		require.NoError(t, err)
+
+		artifact, err := target.Produce(checksum)
+		require.NoError(t, err)
+
+		action := &Action{
+			Target:   target,
+			Artifact: artifact.(*Artifact),
+		}
+		require.Implements(t, (*ark.Action)(nil), action)
+		return action.Artifact, action.Execute(ctx)
+	}
+
+	t.Run("should render json with libraries and external variables", func(t *testing.T) {
+		artifact, err := execute(newTarget("app.jsonnet"))
+		require.NoError(t, err)
+		require.True(t, artifact.Cacheable())
+		require.Len(t, artifact.RenderedFiles, 1)
+		require.Equal(t, "app.json", filepath.Base(artifact.RenderedFiles[0]))
+
+		rendered, err := os.ReadFile(artifact.RenderedFiles[0])
+		require.NoError(t, err)
+		require.JSONEq(t, `{"kind": "Deployment", "name": "api", "replicas": 3}`, string(rendered))
+	})
+
+	t.Run("should render a yaml stream", func(t *testing.T) {
+		target := newTarget("stream.jsonnet")
+		target.Format = FormatYAML
+
+		artifact, err := execute(target)
+		require.NoError(t, err)
+
+		rendered, err := os.ReadFile(artifact.RenderedFiles[0])
+		require.NoError(t, err)
+		require.Contains(t, string(rendered), "---\n")
+		require.Contains(t, string(rendered), "ConfigMap")
+		require.Contains(t, string(rendered), "Service")
+	})
+
+	t.Run("should compute the hash from the files and libraries", func(t *testing.T) {
+		produce := func() string {
+			target := newTarget("app.jsonnet")
+			require.NoError(t, target.Validate())
+			checksum, err := target.Checksum()
+			require.NoError(t, err)
+			artifact, err := target.Produce(checksum)
+			require.NoError(t, err)
+			return artifact.(*Artifact).Hash
+		}
+
+		initial := produce()
+		require.Equal(t, initial, produce())
+
+		libPath := filepath.Join(realm, "lib", "service.libsonnet")
+		lib, err := os.ReadFile(libPath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(libPath, append(lib, '\n'), 0644))
+		editedLib := produce()
+		require.NotEqual(t, initial, editedLib, "editing a library must change the hash")
+
+		appPath := filepath.Join(realm, "app.jsonnet")
+		app, err := os.ReadFile(appPath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(appPath, append(app, '\n'), 0644))
+		require.NotEqual(t, editedLib, produce(), "editing a rendered file must change the hash")
+	})
+
+	t.Run("should fail to render invalid jsonnet", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(realm, "invalid.jsonnet"), []byte(`{ name: std.extVar("missing") }`), 0644))
+		_, err := execute(newTarget("invalid.jsonnet"))
+		require.Error(t, err)
+	})
+
+	t.Run("should reject invalid targets", func(t *testing.T) {
+		require.Error(t, newTarget().Validate())
+		require.Error(t, newTarget("app.json").Validate())
+		require.Error(t, newTarget("app.jsonnet", "nested/app.jsonnet").Validate())
+
+		invalid := newTarget("app.jsonnet")
+		invalid.Format = "toml"
+		require.Error(t, invalid.Validate())
+	})
+}
@@ -0,0 +1,151 @@
+package jsonnet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/jsonnetutils"
+)
+
+// Type is the string value of the Target type
+const Type = "jsonnet"
+
+const (
+	// FormatJSON renders each file as a single JSON document
+	FormatJSON = "json"
+
+	// FormatYAML renders each file as a YAML stream, the file must evaluate to an array of documents
+	FormatYAML = "yaml"
+)
+
+// Target expresses an intention to render one or more jsonnet files
+type Target struct {
+	ark.RawTarget `mapstructure:",squash"`
+	Files         []string          `json:"files" mapstructure:"files"`
+	LibraryDirs   []string          `json:"libraryDirs" mapstructure:"libraryDirs"`
+	ExtVars       map[string]string `json:"extVars" mapstructure:"extVars"`
+	ExtCode       map[string]string `json:"extCode" mapstructure:"extCode"`
+	Format        string            `json:"format" mapstructure:"format"`
+}
+
+// OutputFormat returns the format of the rendered files, defaults to json
+func (t Target) OutputFormat() string {
+	if t.Format == "" {
+		return FormatJSON
+	}
+	return t.Format
+}
+
+// FilePaths returns the absolute paths of the jsonnet files, relative paths are resolved from the build file directory
+func (t Target) FilePaths() []string {
+	return jsonnetutils.BuildLibrary(t.Dir(), t.Files)
+}
+
+// LibraryPaths returns the absolute paths of the library directories, relative paths are resolved from the build file directory
+func (t Target) LibraryPaths() []string {
+	return jsonnetutils.BuildLibrary(t.Dir(), t.LibraryDirs)
+}
+
+// renderedFileName returns the name of the rendered file for a jsonnet file
+func (t Target) renderedFileName(file string) string {
+	base := filepath.Base(file)
+	return fmt.Sprintf("%s.%s", strings.TrimSuffix(base, filepath.Ext(base)), t.OutputFormat())
+}
+
+// Produce should produce Artifact
+// The contents of the jsonnet files and of every file in the library directories are added to the checksum
+// so the artifact changes when a file it renders or imports is edited
+func (t *Target) Produce(checksum hash.Hash) (ark.Artifact, error) {
+	for _, file := range t.FilePaths() {
+		if err := t.hashFile(checksum, file); err != nil {
+			return nil, errors.Wrapf(err, "failed to hash the jsonnet file %s", file)
+		}
+	}
+
+	for _, dir := range t.LibraryPaths() {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() {
+				return err
+			}
+			return t.hashFile(checksum, path)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to hash the library directory %s", dir)
+		}
+	}
+
+	artifact := &Artifact{
+		RawArtifact: ark.RawArtifact{
+			Key:        t.Key(),
+			Type:       t.Type,
+			Hash:       hex.EncodeToString(checksum.Sum(nil)),
+			Attributes: nil,
+		},
+	}
+
+	cacheDir, err := artifact.CacheDirPath()
+	if err != nil {
+		return artifact, err
+	}
+
+	for _, file := range t.Files {
+		artifact.RenderedFiles = append(artifact.RenderedFiles, filepath.Join(renderedDir(cacheDir), t.renderedFileName(file)))
+	}
+
+	return artifact, nil
+}
+
+// hashFile adds the path of a file relative to the realm and its contents to the checksum
+func (t Target) hashFile(checksum hash.Hash, path string) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(t.Realm, path)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(checksum, "%s:%d:%s\n", filepath.ToSlash(rel), len(contents), contents)
+	return err
+}
+
+// Validate checks if the Target fields are valid
+func (t *Target) Validate() error {
+	if err := t.RawTarget.Validate(); err != nil {
+		return err
+	}
+	if err := t.validateFiles(); err != nil {
+		return err
+	}
+	return validation.ValidateStruct(t,
+		validation.Field(&t.Files, validation.Required),
+		validation.Field(&t.Format, validation.In(FormatJSON, FormatYAML)),
+	)
+}
+
+// validateFiles ensures every file renders to a unique output
+func (t Target) validateFiles() error {
+	seen := map[string]string{}
+	for _, file := range t.Files {
+		if filepath.Ext(file) != ".jsonnet" {
+			return errors.Errorf("%s must have a .jsonnet extension", file)
+		}
+
+		name := t.renderedFileName(file)
+		if previous, ok := seen[name]; ok {
+			return errors.Errorf("%s and %s would both be rendered to %s", previous, file, name)
+		}
+		seen[name] = file
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package kube_secret
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sJSON "k8s.io/apimachinery/pkg/runtime/serializer/json"
+
+	"github.com/myfintech/ark/src/go/lib/ark/kv"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+// Action is the executor for rendering a Kubernetes Secret from the KV store
+type Action struct {
+	Artifact  *Artifact
+	Target    *Target
+	KVStorage kv.Storage
+	Logger    logz.FieldLogger
+}
+
+var _ logz.Injector = &Action{}
+
+// UseLogger injects a logger into the target's action
+func (a *Action) UseLogger(logger logz.FieldLogger) {
+	a.Logger = logger
+}
+
+// UseKVStorage allows the KV Storage client to be injected into the target's action
+func (a *Action) UseKVStorage(client kv.Storage) {
+	a.KVStorage = client
+}
+
+// Execute decrypts each KV path and writes a Secret manifest to the artifact's rendered file path
+func (a Action) Execute(_ context.Context) error {
+	if a.KVStorage == nil {
+		return errors.New("a KV storage client has not been provided")
+	}
+
+	data := map[string][]byte{}
+	for _, path := range a.Target.Paths {
+		values, err := a.decrypt(path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decrypt the KV path %s", path)
+		}
+
+		for key, value := range values {
+			if _, ok := data[key]; ok {
+				return errors.Errorf("the key %s is defined by more than one KV path", key)
+			}
+			data[key] = value
+		}
+	}
+
+	secret := &coreV1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      a.Target.SecretName,
+			Namespace: a.Target.Namespace,
+			Labels:    a.Target.SecretLabels,
+		},
+		Type: coreV1.SecretType(a.Target.SecretType),
+		Data: data,
+	}
+
+	return writeManifest(a.Artifact.RenderedFilePath, secret)
+}
+
+// decrypt reads a KV path through a short lived decrypted file and converts its values to secret data
+func (a Action) decrypt(path string) (map[string][]byte, error) {
+	decryptedFile, err := a.KVStorage.DecryptToFile(path)
+	defer func() {
+		if decryptedFile != "" {
+			_ = os.Remove(decryptedFile)
+		}
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := os.ReadFile(decryptedFile)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	if err = json.Unmarshal(contents, &values); err != nil {
+		return nil, err
+	}
+
+	data := map[string][]byte{}
+	for key, value := range values {
+		if str, ok := value.(string); ok {
+			data[key] = []byte(str)
+			continue
+		}
+
+		// non string values are stored as their JSON representation
+		if data[key], err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func writeManifest(fileName string, secret *coreV1.Secret) error {
+	if err := os.MkdirAll(filepath.Dir(fileName), 0700); err != nil {
+		return err
+	}
+
+	manifest, err := os.OpenFile(fileName, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = manifest.Close()
+	}()
+
+	serializer := k8sJSON.NewSerializerWithOptions(
+		k8sJSON.DefaultMetaFactory, nil, nil, k8sJSON.SerializerOptions{
+			Yaml:   true,
+			Pretty: true,
+			Strict: true,
+		})
+
+	return serializer.Encode(secret, manifest)
+}
@@ -0,0 +1,80 @@
+package kube_secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+// Artifact the result of a successful actions.KubeSecret
+type Artifact struct {
+	ark.RawArtifact  `mapstructure:",squash"`
+	RenderedFilePath string `json:"renderedFilePath" mapstructure:"renderedFilePath"`
+}
+
+var _ ark.Disposer = Artifact{}
+
+// renders counts the runs that retain each rendered manifest, concurrent runs of the same hash share the manifest
+var renders = struct {
+	sync.Mutex
+	count map[string]int
+}{count: make(map[string]int)}
+
+// renderedFilePath returns the location of the rendered Secret manifest
+// The manifest contains plaintext and is written to the temp directory instead of the artifact cache
+func renderedFilePath(hash, secretName string) string {
+	return filepath.Join(os.TempDir(), "ark", "secrets", hash, fmt.Sprintf("%s.yaml", secretName))
+}
+
+// Retain marks the rendered manifest as used by a run
+func (a Artifact) Retain() {
+	renders.Lock()
+	defer renders.Unlock()
+	renders.count[a.RenderedFilePath]++
+}
+
+// Dispose removes the rendered manifest once no run that retained it is walking the graph anymore
+// the dependents of the target consume the manifest during the run so the plaintext never outlives it
+func (a Artifact) Dispose() error {
+	renders.Lock()
+	defer renders.Unlock()
+
+	if renders.count[a.RenderedFilePath]--; renders.count[a.RenderedFilePath] > 0 {
+		return nil
+	}
+	delete(renders.count, a.RenderedFilePath)
+
+	if a.RenderedFilePath == "" {
+		return nil
+	}
+	return os.RemoveAll(filepath.Dir(a.RenderedFilePath))
+}
+
+// Cacheable always returns false because the rendered manifest contains plaintext which must never be cached
+func (a Artifact) Cacheable() bool {
+	return false
+}
+
+// RemotelyCached always returns false because the target is not cacheable
+func (a Artifact) RemotelyCached(_ context.Context) (bool, error) {
+	return false, nil
+}
+
+// LocallyCached always returns false because the target is not cacheable
+func (a Artifact) LocallyCached(_ context.Context) (bool, error) {
+	return false, nil
+}
+
+// Push does not do anything
+func (a Artifact) Push(_ context.Context) error {
+	return nil
+}
+
+// Pull does not do anything
+func (a Artifact) Pull(_ context.Context) error {
+	return nil
+}
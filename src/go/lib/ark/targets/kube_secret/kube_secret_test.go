@@ -0,0 +1,173 @@
+package kube_secret
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/kv"
+	"github.com/stretchr/testify/require"
+
+	"github.com/myfintech/ark/src/go/lib/vault_tools/vault_test_harness"
+)
+
+func TestKubeSecret(t *testing.T) {
+	ctx := context.Background()
+	realm := t.TempDir()
+	cacheHome := t.TempDir()
+
+	require.NoError(t, os.Setenv("ARK_CACHE_HOME", cacheHome))
+	defer func() {
+		_ = os.Unsetenv("ARK_CACHE_HOME")
+	}()
+
+	client, cleanup := vault_test_harness.CreateVaultTestCore(t, false)
+	defer cleanup()
+
+	kvStore := &kv.VaultStorage{
+		Client:        client,
+		FSBasePath:    filepath.Join(realm, ".ark/kv"),
+		EncryptionKey: "domain-key",
+	}
+
+	_, err := kvStore.Put("app/database", map[string]interface{}{
+		"password": "hunter2",
+		"port":     5432,
+	})
+	require.NoError(t, err)
+
+	_, err = kvStore.Put("app/api", map[string]interface{}{
+		"token": "abc123",
+	})
+	require.NoError(t, err)
+
+	newTarget := func(paths ...string) *Target {
+		return &Target{
+			RawTarget: ark.RawTarget{
+				Name:  "secrets",
+				Type:  Type,
+				File:  filepath.Join(realm, "build.ts"),
+				Realm: realm,
+			},
+			SecretName: "app-secrets",
+			Namespace:  "apps",
+			SecretType: "Opaque",
+			Paths:      paths,
+		}
+	}
+
+	produce := func(target *Target) *Artifact {
+		require.NoError(t, target.Validate())
+
+		checksum, err := target.Checksum()
+		require.NoError(t, err)
+
+		artifact, err := target.Produce(checksum)
+		require.NoError(t, err)
+		return artifact.(*Artifact)
+	}
+
+	t.Run("should render a secret manifest from the KV store", func(t *testing.T) {
+		target := newTarget("app/database", "app/api")
+		action := &Action{
+			Target:   target,
+			Artifact: produce(target),
+		}
+		action.UseKVStorage(kvStore)
+		require.Implements(t, (*ark.Action)(nil), action)
+		require.False(t, action.Artifact.Cacheable())
+
+		require.Implements(t, (*ark.Disposer)(nil), action.Artifact)
+		action.Artifact.Retain()
+		require.NoError(t, action.Execute(ctx))
+
+		info, err := os.Stat(action.Artifact.RenderedFilePath)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		manifest, err := os.ReadFile(action.Artifact.RenderedFilePath)
+		require.NoError(t, err)
+		require.Contains(t, string(manifest), "kind: Secret")
+		require.Contains(t, string(manifest), "name: app-secrets")
+		require.Contains(t, string(manifest), "namespace: apps")
+		require.Contains(t, string(manifest), "password: aHVudGVyMg==")
+		require.Contains(t, string(manifest), "port: NTQzMg==")
+		require.Contains(t, string(manifest), "token: YWJjMTIz")
+
+		// nothing may be written to the artifact cache
+		_, err = os.Stat(filepath.Join(cacheHome, "artifacts"))
+		require.True(t, os.IsNotExist(err))
+
+		require.NoError(t, action.Artifact.Dispose())
+		_, err = os.Stat(filepath.Dir(action.Artifact.RenderedFilePath))
+		require.True(t, os.IsNotExist(err), "the plaintext manifest must be removed once the run disposed of it")
+	})
+
+	t.Run("should keep a manifest retained by a concurrent run", func(t *testing.T) {
+		target := newTarget("app/database")
+		action := &Action{
+			Target:   target,
+			Artifact: produce(target),
+		}
+		action.UseKVStorage(kvStore)
+
+		action.Artifact.Retain()
+		action.Artifact.Retain()
+		require.NoError(t, action.Execute(ctx))
+
+		require.NoError(t, action.Artifact.Dispose())
+		require.FileExists(t, action.Artifact.RenderedFilePath)
+
+		require.NoError(t, action.Artifact.Dispose())
+		require.NoFileExists(t, action.Artifact.RenderedFilePath)
+	})
+
+	t.Run("should compute the hash from the ciphertext", func(t *testing.T) {
+		before := produce(newTarget("app/api"))
+
+		_, err := kvStore.Put("app/api", map[string]interface{}{
+			"token": "rotated",
+		})
+		require.NoError(t, err)
+
+		after := produce(newTarget("app/api"))
+		require.NotEqual(t, before.Hash, after.Hash)
+		require.NotEqual(t, before.RenderedFilePath, after.RenderedFilePath)
+	})
+
+	t.Run("should fail if a key is defined by more than one path", func(t *testing.T) {
+		_, err := kvStore.Put("app/duplicate", map[string]interface{}{
+			"token": "duplicate",
+		})
+		require.NoError(t, err)
+
+		target := newTarget("app/api", "app/duplicate")
+		action := &Action{
+			Target:    target,
+			Artifact:  produce(target),
+			KVStorage: kvStore,
+		}
+		require.Error(t, action.Execute(ctx))
+	})
+
+	t.Run("should fail if a KV path does not exist", func(t *testing.T) {
+		target := newTarget("app/missing")
+		require.NoError(t, target.Validate())
+
+		checksum, err := target.Checksum()
+		require.NoError(t, err)
+
+		_, err = target.Produce(checksum)
+		require.Error(t, err)
+	})
+
+	t.Run("should reject invalid targets", func(t *testing.T) {
+		require.Error(t, newTarget().Validate())
+
+		invalid := newTarget("app/api")
+		invalid.SecretName = ""
+		require.Error(t, invalid.Validate())
+	})
+}
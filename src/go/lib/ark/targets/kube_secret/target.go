@@ -0,0 +1,69 @@
+package kube_secret
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+// Type is the string value of the Target type
+const Type = "kube_secret"
+
+// Target expresses the intention to render a Kubernetes Secret from paths in the encrypted KV store
+type Target struct {
+	ark.RawTarget `mapstructure:",squash"`
+	SecretName    string            `json:"secretName" mapstructure:"secretName"`
+	Namespace     string            `json:"namespace" mapstructure:"namespace"`
+	SecretType    string            `json:"secretType" mapstructure:"secretType"`
+	Paths         []string          `json:"paths" mapstructure:"paths"`
+	SecretLabels  map[string]string `json:"secretLabels" mapstructure:"secretLabels"`
+}
+
+// EncryptedFilePath returns the location of the ciphertext for a KV path in the workspace
+func (t Target) EncryptedFilePath(path string) string {
+	return filepath.Join(t.Realm, ".ark", "kv", path)
+}
+
+// Produce should produce Artifact
+// The ciphertext of each KV path is added to the checksum so the artifact changes when a secret is edited
+func (t *Target) Produce(checksum hash.Hash) (ark.Artifact, error) {
+	for _, path := range t.Paths {
+		ciphertext, err := os.ReadFile(t.EncryptedFilePath(path))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the encrypted KV path %s", path)
+		}
+		if _, err = fmt.Fprintf(checksum, "%s:%s\n", path, ciphertext); err != nil {
+			return nil, err
+		}
+	}
+
+	shasum := hex.EncodeToString(checksum.Sum(nil))
+
+	return &Artifact{
+		RenderedFilePath: renderedFilePath(shasum, t.SecretName),
+		RawArtifact: ark.RawArtifact{
+			Key:        t.Key(),
+			Type:       t.Type,
+			Hash:       shasum,
+			Attributes: nil,
+		},
+	}, nil
+}
+
+// Validate checks if the Target fields are valid
+func (t *Target) Validate() error {
+	if err := t.RawTarget.Validate(); err != nil {
+		return err
+	}
+	return validation.ValidateStruct(t,
+		validation.Field(&t.SecretName, validation.Required),
+		validation.Field(&t.Paths, validation.Required, validation.Each(validation.Required)),
+	)
+}
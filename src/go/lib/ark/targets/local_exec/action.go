@@ -0,0 +1,107 @@
+package local_exec
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/exec"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+// Action is the executor for running a command on the host
+type Action struct {
+	Artifact          *Artifact
+	Target            *Target
+	Logger            logz.FieldLogger
+	DependencyOutputs []string
+}
+
+var _ logz.Injector = &Action{}
+var _ ark.DependencyOutputsUser = &Action{}
+
+// UseLogger injects a logger into the target's action
+func (a *Action) UseLogger(logger logz.FieldLogger) {
+	a.Logger = logger
+}
+
+// UseDependencyOutputs injects the outputs of the dependencies of the target that are staged into the sandbox
+func (a *Action) UseDependencyOutputs(paths []string) {
+	a.DependencyOutputs = paths
+}
+
+// Execute runs the command, the graph walker captures the declared outputs of the target after it succeeds
+func (a Action) Execute(ctx context.Context) error {
+	if a.Target.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(a.Target.TimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+
+	if a.Target.Sandbox {
+		return a.executeSandboxed(ctx)
+	}
+
+	cmd := exec.LocalExecutor(exec.LocalExecOptions{
+		Context:          ctx,
+		Command:          append([]string{a.Target.Command}, a.Target.Args...),
+		Dir:              a.Target.WorkingDir(),
+		Environment:      a.Target.Environment,
+		Stdout:           os.Stdout,
+		Stderr:           os.Stderr,
+		InheritParentEnv: true,
+	})
+	return a.run(ctx, cmd)
+}
+
+// executeSandboxed runs the command in an execroot that only contains the declared inputs of the target
+// the declared outputs are copied back into the workspace after the command succeeds
+func (a Action) executeSandboxed(ctx context.Context) error {
+	sandbox, err := ark.NewSandbox(a.Target.Realm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = sandbox.Remove()
+	}()
+
+	if err = sandbox.Stage(a.Target.SourceFiles, a.Target.IgnoreFileNotExistsError); err != nil {
+		return err
+	}
+	if err = sandbox.Stage(a.DependencyOutputs, false); err != nil {
+		return err
+	}
+
+	cmd, err := sandbox.Command(ctx, append([]string{a.Target.Command}, a.Target.Args...), a.Target.WorkingDir(), a.Target.Environment)
+	if err != nil {
+		return err
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err = a.run(ctx, cmd); err != nil {
+		return err
+	}
+	return sandbox.Collect(a.Target.OutputPaths())
+}
+
+// run runs the command and reports a timeout of the target
+func (a Action) run(ctx context.Context, cmd *osexec.Cmd) error {
+	if a.Logger != nil {
+		cmd.Stdout = a.Logger
+		cmd.Stderr = a.Logger
+		a.Logger.Infof("executing %s in %s", cmd.String(), cmd.Dir)
+	}
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.Errorf("%s timed out after %ds", a.Target.Key(), a.Target.TimeoutSeconds)
+		}
+		return errors.Wrapf(err, "%s failed", cmd.String())
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package local_exec
+
+import (
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+// Artifact the result of a successful local_exec action
+// The local and remote cache behavior is inherited from ark.RawArtifact so the declared outputs of the target are pushed and pulled with the artifact
+type Artifact struct {
+	ark.RawArtifact `mapstructure:",squash"`
+}
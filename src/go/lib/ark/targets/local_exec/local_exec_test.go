@@ -0,0 +1,156 @@
+package local_exec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalExec(t *testing.T) {
+	ctx := context.Background()
+	realm := t.TempDir()
+
+	require.NoError(t, os.Setenv("ARK_CACHE_HOME", t.TempDir()))
+	defer func() {
+		_ = os.Unsetenv("ARK_CACHE_HOME")
+	}()
+
+	newTarget := func(script string, outputs ...string) *Target {
+		return &Target{
+			RawTarget: ark.RawTarget{
+				Name:    "codegen",
+				Type:    Type,
+				File:    filepath.Join(realm, "build.ts"),
+				Realm:   realm,
+				Outputs: outputs,
+			},
+			Command:     "/bin/sh",
+			Args:        []string{"-c", script},
+			Environment: map[string]string{"GREETING": "hello"},
+			Dir:         "workdir",
+		}
+	}
+
+	execute := func(target *Target, dependencyOutputs ...string) (*Artifact, error) {
+		require.NoError(t, os.MkdirAll(target.WorkingDir(), 0755))
+		require.NoError(t, target.Validate())
+
+		checksum, err := target.Checksum()
+		require.NoError(t, err)
+
+		artifact, err := target.Produce(checksum)
+		require.NoError(t, err)
+
+		action := &Action{
+			Target:   target,
+			Artifact: artifact.(*Artifact),
+		}
+		require.Implements(t, (*ark.Action)(nil), action)
+		action.UseDependencyOutputs(dependencyOutputs)
+		return action.Artifact, action.Execute(ctx)
+	}
+
+	t.Run("should run the command in the working directory", func(t *testing.T) {
+		target := newTarget(`mkdir -p gen && printenv GREETING > gen/greeting.txt`)
+		artifact, err := execute(target)
+		require.NoError(t, err)
+		require.True(t, artifact.Cacheable())
+
+		greeting, err := os.ReadFile(filepath.Join(realm, "workdir", "gen", "greeting.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "hello\n", string(greeting))
+	})
+
+	t.Run("should capture declared outputs relative to the working directory", func(t *testing.T) {
+		target := newTarget(`mkdir -p gen && printenv GREETING > gen/greeting.txt && echo main > main.go`, "gen", "main.go")
+		artifact, err := execute(target)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			filepath.Join(realm, "workdir", "gen"),
+			filepath.Join(realm, "workdir", "main.go"),
+		}, target.OutputPaths())
+
+		require.NoError(t, artifact.CaptureOutputs(target.OutputsRoot(), target.Outputs))
+
+		outputsDir, err := artifact.OutputsDir()
+		require.NoError(t, err)
+
+		greeting, err := os.ReadFile(filepath.Join(outputsDir, "gen", "greeting.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "hello\n", string(greeting))
+		require.FileExists(t, filepath.Join(outputsDir, "main.go"))
+	})
+
+	t.Run("should fail if a declared output is missing", func(t *testing.T) {
+		target := newTarget("true", "missing.txt")
+		artifact, err := execute(target)
+		require.NoError(t, err)
+		require.Error(t, artifact.CaptureOutputs(target.OutputsRoot(), target.Outputs))
+	})
+
+	t.Run("should reject outputs outside of the working directory", func(t *testing.T) {
+		require.Error(t, newTarget("true", "../escape").Validate())
+		require.Error(t, newTarget("true", "/etc/passwd").Validate())
+	})
+
+	t.Run("should fail if the command exits with an error", func(t *testing.T) {
+		_, err := execute(newTarget("exit 3"))
+		require.Error(t, err)
+	})
+
+	t.Run("should fail if the command times out", func(t *testing.T) {
+		target := newTarget("sleep 5")
+		target.TimeoutSeconds = 1
+		_, err := execute(target)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "timed out")
+	})
+
+	t.Run("should only expose declared inputs in a sandbox", func(t *testing.T) {
+		if _, err := ark.SandboxIsolation(); err != nil {
+			t.Skip(err)
+		}
+
+		require.NoError(t, os.MkdirAll(filepath.Join(realm, "dep"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(realm, "input.txt"), []byte("input\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(realm, "dep", "gen.txt"), []byte("generated\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(realm, "secret.txt"), []byte("secret\n"), 0644))
+		require.NoError(t, os.Setenv("ARK_SANDBOX_LEAK", "leak"))
+		defer func() {
+			_ = os.Unsetenv("ARK_SANDBOX_LEAK")
+		}()
+
+		newSandboxedTarget := func(script string) *Target {
+			target := newTarget(script)
+			target.Sandbox = true
+			target.SourceFiles = []string{"input.txt"}
+			target.Outputs = []string{"out.txt"}
+			return target
+		}
+
+		_, err := execute(
+			newSandboxedTarget(`if printenv ARK_SANDBOX_LEAK; then exit 1; fi; printenv GREETING | cat ../input.txt ../dep/gen.txt - > out.txt`),
+			filepath.Join(realm, "dep", "gen.txt"),
+		)
+		require.NoError(t, err)
+
+		out, err := os.ReadFile(filepath.Join(realm, "workdir", "out.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "input\ngenerated\nhello\n", string(out))
+
+		_, err = execute(newSandboxedTarget(`cat ../secret.txt > out.txt`))
+		require.Error(t, err)
+
+		_, err = execute(newSandboxedTarget(`cat ` + filepath.Join(realm, "secret.txt") + ` > out.txt`))
+		require.Error(t, err, "undeclared inputs are hidden from absolute paths")
+
+		_, err = execute(newSandboxedTarget(`echo "not declared" > other.txt`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "was not produced")
+	})
+}
@@ -0,0 +1,93 @@
+package local_exec
+
+import (
+	"encoding/hex"
+	"hash"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/myfintech/ark/src/go/lib/ark"
+)
+
+// Type is the string value of the Target type
+const Type = "local_exec"
+
+// Target expresses the intention to run a command on the host as a cached graph node
+// The files the command produces should be declared as outputs of the raw target relative to the working directory
+// so they are restored on a cache hit
+// With Sandbox the command runs in a temporary execroot that only contains the source files and the outputs of the dependencies of the target
+type Target struct {
+	ark.RawTarget  `mapstructure:",squash"`
+	Command        string            `json:"command" mapstructure:"command"`
+	Args           []string          `json:"args" mapstructure:"args"`
+	Environment    map[string]string `json:"environment" mapstructure:"environment"`
+	Dir            string            `json:"dir" mapstructure:"dir"`
+	TimeoutSeconds int               `json:"timeoutSeconds" mapstructure:"timeoutSeconds"`
+	Sandbox        bool              `json:"sandbox" mapstructure:"sandbox"`
+}
+
+// WorkingDir returns the directory the command runs in
+// A relative Dir is resolved from the directory of the build file
+func (t Target) WorkingDir() string {
+	if t.Dir == "" {
+		return t.RawTarget.Dir()
+	}
+	if filepath.IsAbs(t.Dir) {
+		return t.Dir
+	}
+	return filepath.Join(t.RawTarget.Dir(), t.Dir)
+}
+
+// OutputsRoot the declared outputs are relative to the directory the command runs in
+func (t Target) OutputsRoot() string {
+	return t.WorkingDir()
+}
+
+// OutputPaths returns the absolute paths of the declared outputs
+func (t Target) OutputPaths() []string {
+	return ark.OutputPaths(t.OutputsRoot(), t.Outputs)
+}
+
+// Produce should produce Artifact
+func (t *Target) Produce(checksum hash.Hash) (ark.Artifact, error) {
+	return &Artifact{
+		RawArtifact: ark.RawArtifact{
+			Key:        t.Key(),
+			Type:       t.Type,
+			Hash:       hex.EncodeToString(checksum.Sum(nil)),
+			Attributes: nil,
+		},
+	}, nil
+}
+
+// Validate checks if the Target fields are valid
+func (t *Target) Validate() error {
+	if err := t.RawTarget.Validate(); err != nil {
+		return err
+	}
+	if err := t.validateSandbox(); err != nil {
+		return err
+	}
+	return validation.ValidateStruct(t,
+		validation.Field(&t.Command, validation.Required),
+		validation.Field(&t.TimeoutSeconds, validation.Min(0)),
+	)
+}
+
+// validateSandbox a sandboxed command can only run in a directory that is mirrored in the execroot
+func (t Target) validateSandbox() error {
+	if !t.Sandbox {
+		return nil
+	}
+	rel, err := filepath.Rel(t.Realm, t.WorkingDir())
+	if err != nil {
+		return err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.Errorf("the dir %s of sandboxed target %s must be inside of the realm %s", t.Dir, t.Key(), t.Realm)
+	}
+	return nil
+}
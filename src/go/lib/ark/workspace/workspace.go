@@ -39,8 +39,49 @@ type FileSystemConfig struct {
 }
 
 // RemoteCacheConfig configures the workspace remote cache location
+// The URL may be a blob storage URL (gs://, s3://, file://) or an http(s):// remote cache
+// Headers are sent with every request to an http(s) remote cache and may reference environment variables ($TOKEN or ${TOKEN})
+// Pushed artifacts are signed when PrivateKeyPath is set and pulled artifacts must be signed by PublicKey when it is set
 type RemoteCacheConfig struct {
-	URL string `json:"url"`
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers"`
+	PublicKey      string            `json:"public_key"`
+	PrivateKeyPath string            `json:"private_key_path"`
+}
+
+// ExpandedHeaders returns the configured headers with environment variables expanded
+func (r RemoteCacheConfig) ExpandedHeaders() map[string]string {
+	headers := make(map[string]string, len(r.Headers))
+	for key, value := range r.Headers {
+		headers[key] = os.ExpandEnv(value)
+	}
+	return headers
+}
+
+// LocalCacheConfig configures the limits of the local artifact cache
+// MaxAge is a duration string (e.g. 168h) after which an unused artifact is evicted
+type LocalCacheConfig struct {
+	MaxSizeMB int64  `json:"max_size_mb"`
+	MaxAge    string `json:"max_age"`
+}
+
+// SchedulerConfig configures how the graph walker schedules the execution of targets
+// Pools maps the name of a resource pool to its capacity, targets declare the weight they consume from each pool
+// A target that uses a pool without a configured capacity is not limited by that pool
+type SchedulerConfig struct {
+	Pools map[string]int64 `json:"pools"`
+}
+
+// StorageConfig configures the storage backend used by the host server to persist the target graph
+type StorageConfig struct {
+	Driver string `json:"driver"`
+	Path   string `json:"path"`
+}
+
+// BrokerConfig configures the message broker embedded in the host server
+type BrokerConfig struct {
+	// Type the broker type, nats-jetstream retains the graph events of every run so late subscribers can replay them
+	Type string `json:"type"`
 }
 
 // VaultConfig allows user to set Vault address that's not reliant on an env var
@@ -82,6 +123,10 @@ type Config struct {
 	Vault                VaultConfig        `json:"vault"`
 	FileSystem           FileSystemConfig   `json:"file_system"`
 	RemoteCache          RemoteCacheConfig  `json:"remote_cache"`
+	LocalCache           LocalCacheConfig   `json:"local_cache"`
+	Scheduler            SchedulerConfig    `json:"scheduler"`
+	Storage              StorageConfig      `json:"storage"`
+	Broker               BrokerConfig       `json:"broker"`
 	Plugins              []Plugin           `json:"plugins"`
 	ControlPlane         ControlPlaneConfig `json:"control_plane"`
 	User                 UserConfig         `json:"user"`
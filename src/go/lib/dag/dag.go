@@ -333,9 +333,15 @@ func (g *AcyclicGraph) TopologicalSort(start Vertex) []Vertex {
 
 // Isolate returns an isolated sub graph from the starting vertex
 func (g *AcyclicGraph) Isolate(start Vertex) *AcyclicGraph {
+	return g.IsolateAll(start)
+}
+
+// IsolateAll returns a single isolated sub graph reachable from any of the starting vertices
+// Vertices shared by the starting vertices appear in the sub graph once
+func (g *AcyclicGraph) IsolateAll(starts ...Vertex) *AcyclicGraph {
 	graph := new(AcyclicGraph)
 	nodes := make([]Vertex, 0)
-	_ = g.DepthFirstWalk([]Vertex{start}, func(vertex Vertex, i int) error {
+	_ = g.DepthFirstWalk(starts, func(vertex Vertex, i int) error {
 		nodes = append(nodes, vertex)
 		return nil
 	})
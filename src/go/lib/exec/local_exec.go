@@ -1,6 +1,7 @@
 package exec
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -12,6 +13,8 @@ import (
 
 // LocalExecOptions for executing a command
 type LocalExecOptions struct {
+	// Context kills the process when it is done, the process is not bound to a context if it is nil
+	Context          context.Context
 	Command          []string
 	Dir              string
 	Environment      map[string]string
@@ -26,7 +29,11 @@ func LocalExecutor(opts LocalExecOptions) *exec.Cmd {
 	var cmd *exec.Cmd
 
 	args := utils.ExpandEnvOnArgs(opts.Command)
-	cmd = exec.Command(args[0], args[1:]...)
+	if opts.Context != nil {
+		cmd = exec.CommandContext(opts.Context, args[0], args[1:]...)
+	} else {
+		cmd = exec.Command(args[0], args[1:]...)
+	}
 	log.Debugf("Entering working directory %s", opts.Dir)
 	log.Debugf("Executing %s", cmd.String())
 
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+
+	"github.com/pkg/errors"
 )
 
 // Download pulls a file from the internet
@@ -23,6 +25,11 @@ func Download(url, downloadDir string, perms os.FileMode) (downloadedFileName st
 		_ = resp.Body.Close()
 	}()
 
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = errors.Errorf("failed to download %s: %s", url, resp.Status)
+		return
+	}
+
 	fileHandle, err := os.OpenFile(downloadedFileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perms)
 	if err != nil {
 		return
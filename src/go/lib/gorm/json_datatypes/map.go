@@ -29,3 +29,26 @@ func (m MapStringInterface) GormDataType() string {
 func (MapStringInterface) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
 	return DetermineDBDataType(db)
 }
+
+// MapStringInt64 defined JSON data type, need to implements driver.Valuer, sql.Scanner interface
+type MapStringInt64 map[string]int64
+
+// Value return json value, implement driver.Valuer interface
+func (m MapStringInt64) Value() (driver.Value, error) {
+	return MarshalString(&m)
+}
+
+// Scan scan value into Jsonb, implements sql.Scanner interface
+func (m *MapStringInt64) Scan(val interface{}) error {
+	return Scan(val, m)
+}
+
+// GormDataType gorm common data type
+func (m MapStringInt64) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType gorm db data type
+func (MapStringInt64) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	return DetermineDBDataType(db)
+}
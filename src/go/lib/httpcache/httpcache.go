@@ -0,0 +1,169 @@
+package httpcache
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+// Handler is a minimal http remote cache backed by a directory
+// It implements the protocol expected by cloudutils for http(s) remote cache URLs
+//
+//	HEAD   /<key> 200 if the blob exists, 404 if it does not
+//	GET    /<key> responds with the blob contents
+//	PUT    /<key> stores the request body as the blob
+//	DELETE /<key> removes the blob
+type Handler struct {
+	// Dir the directory blobs are stored in
+	Dir string
+
+	// Token if set every request must supply an "Authorization: Bearer <token>" header
+	Token string
+
+	Logger logz.FieldLogger
+}
+
+// NewHandler creates the storage directory and returns a Handler
+func NewHandler(dir, token string, logger logz.FieldLogger) (*Handler, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Handler{
+		Dir:    dir,
+		Token:  token,
+		Logger: logger,
+	}, nil
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	blobPath, err := h.blobPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		h.read(w, r, blobPath)
+	case http.MethodPut:
+		h.write(w, r, blobPath)
+	case http.MethodDelete:
+		h.delete(w, blobPath)
+	default:
+		w.Header().Set("Allow", "HEAD, GET, PUT, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	if h.Token == "" {
+		return true
+	}
+	expected := "Bearer " + h.Token
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) == 1
+}
+
+// blobPath maps a request path to a file in the storage directory
+// keys may not escape the storage directory
+func (h *Handler) blobPath(urlPath string) (string, error) {
+	key := strings.TrimPrefix(filepath.Clean("/"+urlPath), "/")
+	if key == "" || key == "." {
+		return "", errors.New("a blob key is required")
+	}
+	return filepath.Join(h.Dir, filepath.FromSlash(key)), nil
+}
+
+func (h *Handler) read(w http.ResponseWriter, r *http.Request, blobPath string) {
+	file, err := os.Open(blobPath)
+	if os.IsNotExist(err) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	stat, err := file.Stat()
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	if stat.IsDir() {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, "", stat.ModTime(), file)
+}
+
+// write stores the body in a temporary file and renames it into place
+// so concurrent readers never observe a partially written blob
+func (h *Handler) write(w http.ResponseWriter, r *http.Request, blobPath string) {
+	if err := os.MkdirAll(filepath.Dir(blobPath), 0755); err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(blobPath), ".upload-*")
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = io.Copy(tmp, r.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	if err = os.Rename(tmp.Name(), blobPath); err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (h *Handler) delete(w http.ResponseWriter, blobPath string) {
+	err := os.Remove(blobPath)
+	if os.IsNotExist(err) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) internalError(w http.ResponseWriter, err error) {
+	if h.Logger != nil {
+		h.Logger.Error(err)
+	}
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -3,6 +3,7 @@ package cloudutils
 import (
 	"context"
 	"io"
+	"strings"
 
 	"github.com/pkg/errors"
 	"gocloud.dev/blob"
@@ -17,12 +18,54 @@ import (
 
 var appCTX = appcontext.Context()
 
+// Committer is implemented by writers that only complete an upload when committed
+// The error returned by Commit must be checked to ensure the upload succeeded
+type Committer interface {
+	Commit() error
+}
+
+// BlobOption configures optional behavior of a blob operation
+type BlobOption func(o *blobOptions)
+
+type blobOptions struct {
+	headers map[string]string
+}
+
+// WithHeaders adds headers to every request made against an http(s) remote cache
+func WithHeaders(headers map[string]string) BlobOption {
+	return func(o *blobOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]string)
+		}
+		for key, value := range headers {
+			o.headers[key] = value
+		}
+	}
+}
+
+func newBlobOptions(opts []BlobOption) blobOptions {
+	o := blobOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// IsHTTP returns true if the bucket URL refers to an http(s) remote cache rather than a blob storage bucket
+func IsHTTP(bucketURL string) bool {
+	return strings.HasPrefix(bucketURL, "http://") || strings.HasPrefix(bucketURL, "https://")
+}
+
 // BlobCheck queries a binary storage location for a designated file
-func BlobCheck(ctx context.Context, bucketURL, checkString string) (bool, error) {
+func BlobCheck(ctx context.Context, bucketURL, checkString string, opts ...BlobOption) (bool, error) {
 	if ctx == nil {
 		ctx = appCTX
 	}
 
+	if IsHTTP(bucketURL) {
+		return httpBlobCheck(ctx, bucketURL, checkString, newBlobOptions(opts))
+	}
+
 	bucket, err := blob.OpenBucket(ctx, bucketURL)
 	if err != nil {
 		return false, errors.Wrap(err, "unable to open bucket for check")
@@ -33,11 +76,15 @@ func BlobCheck(ctx context.Context, bucketURL, checkString string) (bool, error)
 }
 
 // NewBlobReader pulls a file from a binary storage location
-func NewBlobReader(ctx context.Context, bucketURL, fileKey string) (io.Reader, func(), error) {
+func NewBlobReader(ctx context.Context, bucketURL, fileKey string, opts ...BlobOption) (io.Reader, func(), error) {
 	if ctx == nil {
 		ctx = appCTX
 	}
 
+	if IsHTTP(bucketURL) {
+		return newHTTPBlobReader(ctx, bucketURL, fileKey, newBlobOptions(opts))
+	}
+
 	bucket, err := blob.OpenBucket(ctx, bucketURL)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "unable to open bucket for check")
@@ -55,11 +102,16 @@ func NewBlobReader(ctx context.Context, bucketURL, fileKey string) (io.Reader, f
 }
 
 // NewBlobWriter pushes a file from a local location to a binary storage location
-func NewBlobWriter(ctx context.Context, bucketURL, fileKey string) (io.Writer, func(), error) {
+// If the returned writer implements Committer it must be committed to complete the upload
+func NewBlobWriter(ctx context.Context, bucketURL, fileKey string, opts ...BlobOption) (io.Writer, func(), error) {
 	if ctx == nil {
 		ctx = appCTX
 	}
 
+	if IsHTTP(bucketURL) {
+		return newHTTPBlobWriter(ctx, bucketURL, fileKey, newBlobOptions(opts))
+	}
+
 	bucket, err := blob.OpenBucket(ctx, bucketURL)
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "unable to open bucket for writing to remote destination")
@@ -77,11 +129,15 @@ func NewBlobWriter(ctx context.Context, bucketURL, fileKey string) (io.Writer, f
 }
 
 // DeleteBlob removes a blob from a bucket
-func DeleteBlob(ctx context.Context, bucketURL, fileKey string) error {
+func DeleteBlob(ctx context.Context, bucketURL, fileKey string, opts ...BlobOption) error {
 	if ctx == nil {
 		ctx = appCTX
 	}
 
+	if IsHTTP(bucketURL) {
+		return deleteHTTPBlob(ctx, bucketURL, fileKey, newBlobOptions(opts))
+	}
+
 	bucket, err := blob.OpenBucket(ctx, bucketURL)
 	defer func() {
 		_ = bucket.Close()
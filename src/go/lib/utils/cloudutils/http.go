@@ -0,0 +1,207 @@
+package cloudutils
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/myfintech/ark/src/go/lib/log"
+)
+
+// HTTP remote caches implement a minimal protocol compatible with Bazel/Gradle style caches
+//
+//	HEAD   <base>/<key> 200 if the blob exists, 404 if it does not
+//	GET    <base>/<key> responds with the blob contents
+//	PUT    <base>/<key> stores the request body as the blob
+//	DELETE <base>/<key> removes the blob
+//
+// The client has no overall timeout because blobs are streamed and can be large
+// instead connecting and waiting for the response headers are bounded so an unresponsive cache fails the request
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConnsPerHost:   16,
+	},
+}
+
+func httpBlobURL(baseURL, key string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(key, "/")
+}
+
+func newHTTPRequest(ctx context.Context, method, baseURL, key string, body io.Reader, opts blobOptions) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, httpBlobURL(baseURL, key), body)
+	if err != nil {
+		return nil, err
+	}
+
+	for header, value := range opts.headers {
+		req.Header.Set(header, value)
+	}
+	return req, nil
+}
+
+func unexpectedStatus(res *http.Response) error {
+	return errors.Errorf("%s %s request failed with status %s",
+		res.Request.Method,
+		res.Request.URL,
+		res.Status,
+	)
+}
+
+func httpBlobCheck(ctx context.Context, baseURL, key string, opts blobOptions) (bool, error) {
+	req, err := newHTTPRequest(ctx, http.MethodHead, baseURL, key, nil, opts)
+	if err != nil {
+		return false, err
+	}
+
+	log.Debugf("checking existence of %s", req.URL)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to reach remote cache for check")
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, unexpectedStatus(res)
+	}
+}
+
+func newHTTPBlobReader(ctx context.Context, baseURL, key string, opts blobOptions) (io.Reader, func(), error) {
+	req, err := newHTTPRequest(ctx, http.MethodGet, baseURL, key, nil, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Debugf("preparing reader for %s", req.URL)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to reach remote cache for reading")
+	}
+
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		return nil, nil, unexpectedStatus(res)
+	}
+
+	return res.Body, func() {
+		_ = res.Body.Close()
+	}, nil
+}
+
+// errUploadAborted the error an upload is aborted with when the writer is closed before it was committed
+var errUploadAborted = errors.New("upload aborted before it was committed")
+
+// httpBlobWriter streams writes into the body of a PUT request
+type httpBlobWriter struct {
+	pipe *io.PipeWriter
+	done chan error
+	once sync.Once
+	err  error
+}
+
+// Write writes to the request body
+func (w *httpBlobWriter) Write(p []byte) (int, error) {
+	return w.pipe.Write(p)
+}
+
+// Close aborts the request body unless the upload was already committed
+// It is safe to call Close multiple times
+func (w *httpBlobWriter) Close() error {
+	return w.finish(errUploadAborted)
+}
+
+// Commit ends the request body and returns any error reported by the remote cache
+func (w *httpBlobWriter) Commit() error {
+	return w.finish(nil)
+}
+
+// finish closes the request body with the given error and waits for the response of the remote cache
+// closing the body with an error fails the request so the remote cache never stores a truncated blob
+func (w *httpBlobWriter) finish(cause error) error {
+	w.once.Do(func() {
+		_ = w.pipe.CloseWithError(cause)
+		w.err = <-w.done
+		if cause != nil && w.err == nil {
+			w.err = cause
+		}
+	})
+	return w.err
+}
+
+func newHTTPBlobWriter(ctx context.Context, baseURL, key string, opts blobOptions) (io.Writer, func(), error) {
+	reader, writer := io.Pipe()
+
+	req, err := newHTTPRequest(ctx, http.MethodPut, baseURL, key, reader, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	blobWriter := &httpBlobWriter{
+		pipe: writer,
+		done: make(chan error, 1),
+	}
+
+	log.Debugf("preparing writer for %s", req.URL)
+	go func() {
+		res, doErr := httpClient.Do(req)
+		if doErr != nil {
+			doErr = errors.Wrap(doErr, "unable to reach remote cache for writing")
+		} else {
+			_ = res.Body.Close()
+			if res.StatusCode < 200 || res.StatusCode > 299 {
+				doErr = unexpectedStatus(res)
+			}
+		}
+		// unblocks any pending writes if the remote cache responded before the body was consumed
+		_ = reader.CloseWithError(doErr)
+		blobWriter.done <- doErr
+	}()
+
+	return blobWriter, func() {
+		_ = blobWriter.Close()
+	}, nil
+}
+
+func deleteHTTPBlob(ctx context.Context, baseURL, key string, opts blobOptions) error {
+	req, err := newHTTPRequest(ctx, http.MethodDelete, baseURL, key, nil, opts)
+	if err != nil {
+		return err
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "unable to reach remote cache for deletion")
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return unexpectedStatus(res)
+	}
+}
@@ -0,0 +1,92 @@
+package cloudutils
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/myfintech/ark/src/go/lib/httpcache"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+func TestHTTPRemoteCache(t *testing.T) {
+	ctx := context.Background()
+	cacheDir := "/tmp/cloudutils_http_testing"
+	defer func() {
+		_ = os.RemoveAll(cacheDir)
+	}()
+
+	handler, err := httpcache.NewHandler(cacheDir, "secret", logz.NoOpLogger{})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	baseURL := fmt.Sprintf("%s/cas", server.URL)
+	auth := WithHeaders(map[string]string{"Authorization": "Bearer secret"})
+	key := "1234567890.tar.gz"
+
+	t.Run("should reject unauthorized requests", func(t *testing.T) {
+		_, checkErr := BlobCheck(ctx, baseURL, key)
+		require.Error(t, checkErr)
+	})
+
+	t.Run("should report a missing blob", func(t *testing.T) {
+		exists, checkErr := BlobCheck(ctx, baseURL, key, auth)
+		require.NoError(t, checkErr)
+		require.False(t, exists)
+	})
+
+	t.Run("should be able to write a blob", func(t *testing.T) {
+		writer, cleanup, writeErr := NewBlobWriter(ctx, baseURL, key, auth)
+		require.NoError(t, writeErr)
+		defer cleanup()
+
+		_, writeErr = fmt.Fprint(writer, "This is a test")
+		require.NoError(t, writeErr)
+		require.Implements(t, (*Committer)(nil), writer)
+		require.NoError(t, writer.(Committer).Commit())
+		require.FileExists(t, filepath.Join(cacheDir, "cas", key))
+	})
+
+	t.Run("should not store a blob that was not committed", func(t *testing.T) {
+		abortedKey := "0987654321.tar.gz"
+		writer, cleanup, writeErr := NewBlobWriter(ctx, baseURL, abortedKey, auth)
+		require.NoError(t, writeErr)
+
+		_, writeErr = fmt.Fprint(writer, "This is a trunc")
+		require.NoError(t, writeErr)
+		cleanup()
+
+		exists, checkErr := BlobCheck(ctx, baseURL, abortedKey, auth)
+		require.NoError(t, checkErr)
+		require.False(t, exists)
+	})
+
+	t.Run("should be able to read a blob", func(t *testing.T) {
+		exists, checkErr := BlobCheck(ctx, baseURL, key, auth)
+		require.NoError(t, checkErr)
+		require.True(t, exists)
+
+		reader, cleanup, readErr := NewBlobReader(ctx, baseURL, key, auth)
+		require.NoError(t, readErr)
+		defer cleanup()
+
+		data, readErr := ioutil.ReadAll(reader)
+		require.NoError(t, readErr)
+		require.Equal(t, "This is a test", string(data))
+	})
+
+	t.Run("should be able to delete a blob", func(t *testing.T) {
+		require.NoError(t, DeleteBlob(ctx, baseURL, key, auth))
+		exists, checkErr := BlobCheck(ctx, baseURL, key, auth)
+		require.NoError(t, checkErr)
+		require.False(t, exists)
+	})
+}
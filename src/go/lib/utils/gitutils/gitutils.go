@@ -1,6 +1,10 @@
 package gitutils
 
 import (
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
@@ -44,3 +48,85 @@ func getPathToChange(change *object.Change) string {
 	}
 	return change.To.Name
 }
+
+// ChangedFiles returns the absolute paths of the files that changed between the merge base of HEAD and rev
+// The repository is discovered by walking up from path
+// If includeWorktree is true uncommitted and untracked files in the worktree are included
+func ChangedFiles(path, rev string, includeWorktree bool) ([]string, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open git repository at %s", path)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	root := worktree.Filesystem.Root()
+
+	headRef, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := repo.CommitObject(headRef.Hash())
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := commitFromRev(repo, rev)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve revision %s", rev)
+	}
+
+	ancestors, err := commonAncestors(head, base)
+	if err != nil {
+		return nil, err
+	}
+	if len(ancestors) == 0 {
+		return nil, errors.Errorf("HEAD and %s do not share a common ancestor", rev)
+	}
+
+	ancestorTree, err := ancestors[0].Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	headTree, err := head.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTree(ancestorTree, headTree)
+	if err != nil {
+		return nil, err
+	}
+
+	unique := make(map[string]bool)
+	for _, change := range changes {
+		// renames produce a change with both names, either may belong to a target
+		unique[getPathToChange(change)] = true
+		if change.To.Name != "" {
+			unique[change.To.Name] = true
+		}
+	}
+
+	if includeWorktree {
+		status, statusErr := worktree.Status()
+		if statusErr != nil {
+			return nil, statusErr
+		}
+		for file, fileStatus := range status {
+			if fileStatus.Staging != git.Unmodified || fileStatus.Worktree != git.Unmodified {
+				unique[file] = true
+			}
+		}
+	}
+
+	files := make([]string, 0, len(unique))
+	for file := range unique {
+		files = append(files, filepath.Join(root, filepath.FromSlash(file)))
+	}
+	sort.Strings(files)
+	return files, nil
+}
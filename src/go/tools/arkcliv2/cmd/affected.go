@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/affected"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs/messages"
+	"github.com/myfintech/ark/src/go/lib/ark/run_report"
+	"github.com/myfintech/ark/src/go/lib/ark/storage/graph"
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server"
+	"github.com/myfintech/ark/src/go/lib/ark/workspace"
+	"github.com/myfintech/ark/src/go/lib/daemonize"
+	"github.com/myfintech/ark/src/go/lib/embedded_scripting/typescript"
+	fs2 "github.com/myfintech/ark/src/go/lib/fs"
+	"github.com/myfintech/ark/src/go/lib/kube"
+	"github.com/myfintech/ark/src/go/lib/logz"
+	"github.com/myfintech/ark/src/go/lib/utils/gitutils"
+)
+
+func newAffectedCmd(
+	rootCmd *cobra.Command,
+	logger logz.FieldLogger,
+	config *workspace.Config,
+	vm *typescript.VirtualMachine,
+	serverClient http_server.Client,
+	hostServerDaemon *daemonize.Proc,
+) *cobra.Command {
+	var affectedCmd = &cobra.Command{
+		Use:   "affected [TARGET_PATH...]",
+		Short: "affected lists the targets whose sources changed since a git revision and every target that depends on them",
+		Long: `ark affected --base origin/main src/go/services/my_service/build.ts
+
+The given build files are loaded before the graph is inspected.
+If no build files are given the targets already loaded by the host server are used.`,
+		PersistentPreRunE: cobraRunEMiddleware(
+			ensureServerRunning(hostServerDaemon, logger),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			base, err := cmd.Flags().GetString("base")
+			if err != nil {
+				return err
+			}
+
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+
+			includeWorktree, err := cmd.Flags().GetBool("worktree")
+			if err != nil {
+				return err
+			}
+
+			run, err := cmd.Flags().GetBool("run")
+			if err != nil {
+				return err
+			}
+
+			if output != "keys" && output != "json" {
+				return errors.Errorf("%s is not a valid output format (keys|json)", output)
+			}
+
+			// the progress of the run is written to stdout which would corrupt the json document
+			if output == "json" && run {
+				return errors.New("--output json can't be used with --run")
+			}
+
+			if len(args) > 0 {
+				if err = resolveAffectedEntrypoints(cmd, vm, config, args); err != nil {
+					return err
+				}
+			}
+
+			changedFiles, err := gitutils.ChangedFiles(config.Root(), base, includeWorktree)
+			if err != nil {
+				return err
+			}
+
+			targets, err := serverClient.GetTargets()
+			if err != nil {
+				return err
+			}
+
+			edges, err := serverClient.GetGraphEdges()
+			if err != nil {
+				return err
+			}
+
+			g, err := graph.FromTargetsAndEdges(targets, edges)
+			if err != nil {
+				return err
+			}
+
+			affectedTargets, err := affected.Targets(g, changedFiles)
+			if err != nil {
+				return err
+			}
+
+			switch output {
+			case "json":
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err = encoder.Encode(affectedTargets); err != nil {
+					return err
+				}
+			default:
+				for _, key := range affected.Keys(affectedTargets) {
+					fmt.Println(key)
+				}
+			}
+
+			if !run || len(affectedTargets) == 0 {
+				return nil
+			}
+
+			return runAffectedTargets(cmd, logger, config, serverClient, affected.Roots(affectedTargets))
+		},
+	}
+
+	rootCmd.AddCommand(affectedCmd)
+	affectedCmd.Flags().String("base", "origin/main", "the git revision to compare HEAD against (the merge base of HEAD and the revision is used)")
+	affectedCmd.Flags().StringP("output", "o", "keys", "the output format (keys|json)")
+	affectedCmd.Flags().Bool("worktree", false, "includes uncommitted changes in the worktree")
+	affectedCmd.Flags().Bool("run", false, "runs the affected targets after listing them (not supported with --output json)")
+	affectedCmd.Flags().Bool("push", false, "pushes artifacts after successful actions when used with --run")
+	affectedCmd.Flags().Bool("force", false, "ignores cache and forces action execution when used with --run")
+	affectedCmd.Flags().Bool("ci", false, "disables interactive mode when used with --run")
+	affectedCmd.Flags().Bool("summary", true, "prints the critical path, slowest targets and cache hit rate when used with --run")
+	affectedCmd.Flags().Int("summary-top", run_report.DefaultTopN, "the number of slowest targets included in the summary")
+	affectedCmd.Flags().String("trace", "", "writes the run as Chrome trace event json to the given file when used with --run")
+	return affectedCmd
+}
+
+// resolveAffectedEntrypoints evaluates build files so their targets are loaded by the host server
+func resolveAffectedEntrypoints(cmd *cobra.Command, vm *typescript.VirtualMachine, config *workspace.Config, entrypoints []string) error {
+	k8sNamespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return err
+	}
+
+	environment, err := cmd.Flags().GetString("environment")
+	if err != nil {
+		return err
+	}
+
+	if k8sNamespace == "" {
+		k8sNamespace = config.K8s.Namespace
+	}
+
+	if err = InstallCLIModules(vm, cmd, entrypoints, map[string]interface{}{
+		"namespace":   kube.NormalizeNamespace(k8sNamespace),
+		"context":     "",
+		"environment": environment,
+		"ci":          true,
+	}); err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	for _, entrypoint := range entrypoints {
+		if !filepath.IsAbs(entrypoint) {
+			if entrypoint, err = fs2.NormalizePath(cwd, entrypoint); err != nil {
+				return err
+			}
+		}
+		if _, err = vm.ResolveModule(entrypoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runAffectedTargets requests a single run of every affected root target and follows it until it finishes
+// the roots depend on every other affected target so this rebuilds everything that was affected
+func runAffectedTargets(cmd *cobra.Command, logger logz.FieldLogger, config *workspace.Config, serverClient http_server.Client, keys []string) error {
+	push, err := cmd.Flags().GetBool("push")
+	if err != nil {
+		return err
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return err
+	}
+
+	k8sNamespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return err
+	}
+
+	if k8sNamespace == "" {
+		k8sNamespace = config.K8s.Namespace
+	}
+
+	// the run is followed until it finishes so the exit code reflects the result of the affected targets
+	eventsCtx, cancelEvents := context.WithCancel(context.Background())
+	defer cancelEvents()
+
+	id, stream, err := startRun(eventsCtx, serverClient, messages.GraphRunnerExecuteCommand{
+		TargetKeys:     keys,
+		K8sNamespace:   kube.NormalizeNamespace(k8sNamespace),
+		PushAfterBuild: push,
+		ForceBuild:     force,
+	})
+	if errors.Is(err, http_server.ErrRunFinished) {
+		return reportFinishedRun(cmd, serverClient, logger, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("build ID %s for %s", id, strings.Join(keys, ", "))
+	logBuildLogURL(logger, id)
+
+	return followRun(cmd, serverClient, logger, &ark.Run{ID: id}, stream, true)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func newCacheCmd(rootCmd *cobra.Command) *cobra.Command {
+	var cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "cache is a sub-command of ark that manages the local artifact cache",
+	}
+
+	rootCmd.AddCommand(cacheCmd)
+	return cacheCmd
+}
+
+// formatBytes renders a byte count using binary units (KiB, MiB, GiB ...)
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
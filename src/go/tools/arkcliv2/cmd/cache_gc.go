@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"github.com/cheynewallace/tabby"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/myfintech/ark/src/go/lib/ark/local_cache"
+	"github.com/myfintech/ark/src/go/lib/ark/workspace"
+	"github.com/myfintech/ark/src/go/lib/daemonize"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+func newCacheGCCmd(
+	cacheCmd *cobra.Command,
+	logger logz.FieldLogger,
+	config *workspace.Config,
+	hostServerDaemon *daemonize.Proc,
+) *cobra.Command {
+	var cacheGCCmd = &cobra.Command{
+		Use:   "gc",
+		Short: "gc is a sub-command of cache that evicts artifacts exceeding the configured size and age limits",
+		Long: `gc evicts every artifact which hasn't been used within local_cache.max_age
+and then evicts the least recently used artifacts until the cache fits within local_cache.max_size_mb
+
+The host server collects the cache after its runs, so artifacts are only evicted while it is stopped.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+
+			maxSizeMB, err := cmd.Flags().GetInt64("max-size-mb")
+			if err != nil {
+				return err
+			}
+
+			maxAge, err := cmd.Flags().GetDuration("max-age")
+			if err != nil {
+				return err
+			}
+
+			manager, err := local_cache.NewManager(*config)
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flags().Changed("max-size-mb") {
+				manager.MaxSize = maxSizeMB * 1024 * 1024
+			}
+			if cmd.Flags().Changed("max-age") {
+				manager.MaxAge = maxAge
+			}
+
+			if !manager.Limited() {
+				return errors.New("no cache limits configured; set local_cache in the workspace settings or use --max-size-mb / --max-age")
+			}
+
+			// the host server may be using the artifacts that would be evicted
+			if !dryRun {
+				state, err := hostServerDaemon.Status()
+				if err == nil && state == daemonize.STATE_RUNNING {
+					return errors.New("the host server is running and collects the cache after its runs; stop it with ark server stop or use --dry-run")
+				}
+			}
+
+			result, err := manager.GC(dryRun)
+			if err != nil {
+				return err
+			}
+
+			t := tabby.New()
+			t.AddHeader("artifact", "hash", "size", "last_access")
+			for _, entry := range result.Evicted {
+				t.AddLine(entry.Name, entry.Hash, formatBytes(entry.Size), entry.LastAccess)
+			}
+			t.Print()
+
+			if dryRun {
+				logger.Infof("%d artifacts would be evicted freeing %s", len(result.Evicted), formatBytes(result.Freed))
+				return nil
+			}
+
+			logger.Infof("evicted %d artifacts freeing %s", len(result.Evicted), formatBytes(result.Freed))
+			return nil
+		},
+	}
+
+	cacheCmd.AddCommand(cacheGCCmd)
+	cacheGCCmd.Flags().Bool("dry-run", false, "reports the artifacts that would be evicted without removing them")
+	cacheGCCmd.Flags().Int64("max-size-mb", 0, "overrides local_cache.max_size_mb from the workspace settings")
+	cacheGCCmd.Flags().Duration("max-age", 0, "overrides local_cache.max_age from the workspace settings")
+	return cacheGCCmd
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newCacheKeygenCmd(cacheCmd *cobra.Command) *cobra.Command {
+	var cacheKeygenCmd = &cobra.Command{
+		Use:   "keygen PRIVATE_KEY_PATH",
+		Short: "keygen is a sub-command of cache that generates an ed25519 key pair for signing remote cache artifacts",
+		Long: `keygen writes a base64 encoded ed25519 private key to PRIVATE_KEY_PATH and prints the public key
+set remote_cache.private_key_path on machines that push artifacts and remote_cache.public_key on every machine that pulls them`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyPath := args[0]
+			if _, err := os.Stat(keyPath); err == nil {
+				return errors.Errorf("%s already exists", keyPath)
+			}
+
+			publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				return err
+			}
+
+			if err = os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+				return err
+			}
+
+			encodedPrivateKey := base64.StdEncoding.EncodeToString(privateKey)
+			if err = os.WriteFile(keyPath, []byte(encodedPrivateKey), 0600); err != nil {
+				return err
+			}
+
+			fmt.Println(base64.StdEncoding.EncodeToString(publicKey))
+			return nil
+		},
+	}
+
+	cacheCmd.AddCommand(cacheKeygenCmd)
+	return cacheKeygenCmd
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/myfintech/ark/src/go/lib/httpcache"
+	"github.com/myfintech/ark/src/go/lib/logz"
+	"github.com/myfintech/ark/src/go/lib/xdgbase"
+)
+
+func newCacheServeCmd(
+	cacheCmd *cobra.Command,
+	logger logz.FieldLogger,
+) *cobra.Command {
+	var cacheServeCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "serve is a sub-command of cache that runs an http remote cache backed by a local directory",
+		Long: `serve runs a minimal http remote cache which can be used by setting remote_cache.url in the workspace settings
+e.g. {"remote_cache": {"url": "http://127.0.0.1:9090", "headers": {"Authorization": "Bearer $ARK_REMOTE_CACHE_TOKEN"}}}
+
+The token clients must send is read from --token-file or the ARK_REMOTE_CACHE_TOKEN environment variable.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			addr, err := cmd.Flags().GetString("address")
+			if err != nil {
+				return err
+			}
+
+			dir, err := cmd.Flags().GetString("dir")
+			if err != nil {
+				return err
+			}
+
+			token, err := cacheServeToken(cmd)
+			if err != nil {
+				return err
+			}
+
+			if dir == "" {
+				cacheDir, cacheDirErr := xdgbase.Dir("ark", xdgbase.CacheSuffix)
+				if cacheDirErr != nil {
+					return cacheDirErr
+				}
+				dir = filepath.Join(cacheDir, "remote_cache")
+			}
+
+			handler, err := httpcache.NewHandler(dir, token, logger)
+			if err != nil {
+				return err
+			}
+
+			logger.Infof("serving remote cache from %s on http://%s", dir, addr)
+			return http.ListenAndServe(addr, handler)
+		},
+	}
+
+	cacheCmd.AddCommand(cacheServeCmd)
+	cacheServeCmd.Flags().StringP("address", "a", "127.0.0.1:9090", "The address for the remote cache to listen on")
+	cacheServeCmd.Flags().String("dir", "", "The directory artifacts are stored in [default $ARK_CACHE_HOME/remote_cache]")
+	cacheServeCmd.Flags().String("token-file", "", "A file containing the token clients must send in an 'Authorization: Bearer <token>' header [default $ARK_REMOTE_CACHE_TOKEN]")
+	cacheServeCmd.Flags().String("token", "", "When set clients must send an 'Authorization: Bearer <token>' header")
+	_ = cacheServeCmd.Flags().MarkDeprecated("token", "it exposes the token in the process list, use --token-file or ARK_REMOTE_CACHE_TOKEN instead")
+	return cacheServeCmd
+}
+
+// cacheServeToken returns the token clients of the remote cache must send
+// it is read from --token-file, the deprecated --token flag or ARK_REMOTE_CACHE_TOKEN in that order
+func cacheServeToken(cmd *cobra.Command) (string, error) {
+	tokenFile, err := cmd.Flags().GetString("token-file")
+	if err != nil {
+		return "", err
+	}
+
+	if tokenFile != "" {
+		data, readErr := os.ReadFile(tokenFile)
+		if readErr != nil {
+			return "", errors.Wrap(readErr, "failed to read the token file")
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", errors.Errorf("the token file %s is empty", tokenFile)
+		}
+		return token, nil
+	}
+
+	token, err := cmd.Flags().GetString("token")
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		return token, nil
+	}
+
+	return os.Getenv("ARK_REMOTE_CACHE_TOKEN"), nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/cheynewallace/tabby"
+	"github.com/spf13/cobra"
+
+	"github.com/myfintech/ark/src/go/lib/ark/local_cache"
+	"github.com/myfintech/ark/src/go/lib/ark/workspace"
+)
+
+func newCacheStatsCmd(
+	cacheCmd *cobra.Command,
+	config *workspace.Config,
+) *cobra.Command {
+	var cacheStatsCmd = &cobra.Command{
+		Use:   "stats",
+		Short: "stats is a sub-command of cache that summarizes the contents of the local artifact cache",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := local_cache.NewManager(*config)
+			if err != nil {
+				return err
+			}
+
+			stats, err := manager.Stats()
+			if err != nil {
+				return err
+			}
+
+			maxSize, maxAge := "unlimited", "unlimited"
+			if stats.MaxSize > 0 {
+				maxSize = formatBytes(stats.MaxSize)
+			}
+			if stats.MaxAge > 0 {
+				maxAge = stats.MaxAge.String()
+			}
+
+			t := tabby.New()
+			t.AddLine("dir", stats.Dir)
+			t.AddLine("artifacts", stats.Entries)
+			t.AddLine("size", formatBytes(stats.Size))
+			t.AddLine("max_size", maxSize)
+			t.AddLine("max_age", maxAge)
+			if stats.Entries > 0 {
+				t.AddLine("least_recently_used", stats.OldestUsed.Format(time.RFC3339))
+				t.AddLine("most_recently_used", stats.NewestUsed.Format(time.RFC3339))
+			}
+			t.Print()
+			return nil
+		},
+	}
+
+	cacheCmd.AddCommand(cacheStatsCmd)
+	return cacheStatsCmd
+}
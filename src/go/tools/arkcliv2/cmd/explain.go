@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cheynewallace/tabby"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/derivation"
+	"github.com/myfintech/ark/src/go/lib/ark/local_cache"
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server"
+	"github.com/myfintech/ark/src/go/lib/ark/workspace"
+	"github.com/myfintech/ark/src/go/lib/daemonize"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+func newExplainCmd(
+	rootCmd *cobra.Command,
+	logger logz.FieldLogger,
+	config *workspace.Config,
+	serverClient http_server.Client,
+	hostServerDaemon *daemonize.Proc,
+) *cobra.Command {
+	var explainCmd = &cobra.Command{
+		Use:   "explain TARGET_KEY",
+		Short: "explain reports which attributes, source files or ancestors of a target changed since its last cached build",
+		Long: `ark explain src/go/services/my_service/build.ts:image
+
+The hash inputs of the target loaded by the host server are compared against the inputs recorded
+in the artifact.json of the most recently used artifact of the target in the local cache.`,
+		PersistentPreRunE: cobraRunEMiddleware(
+			ensureServerRunning(hostServerDaemon, logger),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("TARGET_KEY is a required parameter")
+			}
+			key := args[0]
+
+			targets, err := serverClient.GetTargets()
+			if err != nil {
+				return err
+			}
+
+			var rawTarget *ark.RawTarget
+			for i := range targets {
+				if targets[i].Key() == key {
+					rawTarget = &targets[i]
+					break
+				}
+			}
+			if rawTarget == nil {
+				return errors.Errorf("%s is not loaded by the host server", key)
+			}
+
+			rawArtifact, err := derivation.RawArtifactFromRawTarget(*rawTarget)
+			if err != nil {
+				return err
+			}
+
+			cacheDir, err := rawArtifact.CacheDirPath()
+			if err != nil {
+				return err
+			}
+			if _, err = os.Stat(filepath.Join(cacheDir, "artifact.json")); err == nil {
+				fmt.Printf("%s is cached with hash %s and will not be rebuilt\n", key, rawArtifact.ShortHash())
+				return nil
+			}
+
+			manager, err := local_cache.NewManager(*config)
+			if err != nil {
+				return err
+			}
+
+			previous, err := manager.Previous(key, rawArtifact.Hash)
+			if err != nil {
+				return err
+			}
+			if previous == nil {
+				return errors.Errorf("no previous build of %s was found in the local cache", key)
+			}
+			if previous.Inputs == nil || rawArtifact.Inputs == nil {
+				return errors.Errorf("the previous build %s of %s was cached before its hash inputs were recorded", previous.ShortHash(), key)
+			}
+
+			summary := tabby.New()
+			summary.AddLine("target_key", key)
+			summary.AddLine("hash", rawArtifact.ShortHash())
+			summary.AddLine("previous_hash", previous.ShortHash())
+			summary.Print()
+
+			changes := rawArtifact.Inputs.Diff(*previous.Inputs)
+			if len(changes) == 0 {
+				fmt.Printf("none of the recorded inputs changed, the %s target type adds inputs to its hash that are not recorded\n", rawTarget.Type)
+				return nil
+			}
+
+			t := tabby.New()
+			t.AddHeader("kind", "name", "change", "previous", "current")
+			for _, change := range changes {
+				t.AddLine(change.Kind, change.Name, change.Change(), shortInputHash(change.Previous), shortInputHash(change.Current))
+			}
+			t.Print()
+			return nil
+		},
+	}
+
+	rootCmd.AddCommand(explainCmd)
+	return explainCmd
+}
+
+// shortInputHash shortens a recorded input hash to 7 characters
+func shortInputHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[0:7]
+	}
+	return hash
+}
@@ -26,7 +26,7 @@ func newRootCmd() *cobra.Command {
 
 	cobra.OnInitialize(newOnInitSetup(rootCmd))
 	_ = rootCmd.PersistentFlags().StringP("cwd", "C", "", "sets the current working directory")
-	_ = rootCmd.PersistentFlags().String("broker", "nats-embedded", "the message broker to use")
+	_ = rootCmd.PersistentFlags().String("broker", "nats-embedded", "the message broker to use (nats-embedded|nats-jetstream)")
 	_ = rootCmd.PersistentFlags().
 		String("broker-address", "nats://127.0.0.1:4222", "the address of the message broker")
 	_ = rootCmd.PersistentFlags().
@@ -80,9 +80,18 @@ func newArkCLI(rootCmd *cobra.Command, core coreConfig) (*ArkCLI, error) {
 		logger:  core.logger,
 	}
 
+	newAffectedCmd(rootCmd, core.logger, core.config, core.vm, core.httpClient, core.hostServerDaemon)
+
+	cacheCmd := newCacheCmd(rootCmd)
+	newCacheGCCmd(cacheCmd, core.logger, core.config, core.hostServerDaemon)
+	newCacheKeygenCmd(cacheCmd)
+	newCacheServeCmd(cacheCmd, core.logger)
+	newCacheStatsCmd(cacheCmd, core.config)
+
 	checkCmd := newCheckCmd(rootCmd)
 	newCheckGlobCmd(checkCmd, core.logger, core.config, core.gitIgnorePatterns)
 	newCheckIgnoreCmd(checkCmd, core.config, core.fileObserver, core.logger)
+	newExplainCmd(rootCmd, core.logger, core.config, core.httpClient, core.hostServerDaemon)
 	newInitCmd(rootCmd, core.config)
 
 	kvCmd := newKVCmd(rootCmd)
@@ -92,6 +101,10 @@ func newArkCLI(rootCmd *cobra.Command, core coreConfig) (*ArkCLI, error) {
 	newKVImportCmd(kvCmd, core.vaultClient, core.kvStorage, core.config)
 	newKVPutCmd(kvCmd, core.config, core.kvStorage)
 
+	runsCmd := newRunsCmd(rootCmd)
+	newRunsListCmd(runsCmd, core.httpClient)
+	newRunsShowCmd(runsCmd, core.httpClient)
+
 	serverCmd := newServerCmd(rootCmd)
 	newServerRestartCmd(serverCmd, core.logger, core.hostServerDaemon)
 	newServerRunCmd(
@@ -114,7 +127,9 @@ func newArkCLI(rootCmd *cobra.Command, core coreConfig) (*ArkCLI, error) {
 	newVersionCmd(rootCmd, core.logger)
 	newUpgradeCmd(rootCmd, core.logger)
 	newLogsCmd(rootCmd, core.httpClient)
-	newRunCmd(rootCmd, core.logger, core.config, core.vm, core.httpClient, core.hostServerDaemon)
+	runCmd := newRunCmd(rootCmd, core.logger, core.config, core.vm, core.httpClient, core.hostServerDaemon)
+	newRunAttachCmd(runCmd, core.logger, core.httpClient)
+	newRunCancelCmd(runCmd, core.logger, core.httpClient)
 
 	return arkCLI, nil
 }
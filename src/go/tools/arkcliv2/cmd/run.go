@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -9,9 +10,11 @@ import (
 	"time"
 
 	"github.com/myfintech/ark/src/go/lib/ark/cqrs/protocols/nats"
-	nats2 "github.com/nats-io/nats.go"
 
+	"github.com/myfintech/ark/src/go/lib/ark/graph"
+	"github.com/myfintech/ark/src/go/lib/ark/run_report"
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server"
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/run_recorder"
 
 	"github.com/myfintech/ark/src/go/lib/ark/workspace"
 
@@ -33,6 +36,7 @@ import (
 	"github.com/myfintech/ark/src/go/lib/embedded_scripting/typescript"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
 
 	"golang.org/x/sync/errgroup"
 
@@ -50,11 +54,13 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var natsDisconnected = make(chan error, 1)
+// errRunEventsLost is returned when the event stream of a run ends before the run finished
+var errRunEventsLost = errors.New("lost the connection to the event stream of the run")
 
 func logBuildLogURL(logger logz.FieldLogger, buildId string) {
 	logger.Infof("$$$ ==> ||  Run in terminal: ark logs %s", buildId)
 	logger.Infof("*** ==> ||  Build Log URL: http://127.0.0.1:9000/server/logs/%s", buildId)
+	logger.Infof("@@@ ==> ||  Dashboard URL: http://127.0.0.1:9000/dashboard")
 	if path, err := logz.SuggestedFilePath("ark/graph", buildId); err == nil {
 		logger.Infof("### ==> ||  logs are located at: %s", path)
 	}
@@ -70,46 +76,43 @@ func newRunCmd(
 	hostServerDaemon *daemonize.Proc,
 ) *cobra.Command {
 	var runCmd = &cobra.Command{
-		Use:     "run TARGET_PATH TARGET_NAME",
-		Short:   "run executes the target entrypoint",
-		Long:    `ark run src/go/services/my_service/build.ts goModules`,
+		Use:   "run {TARGET_PATH TARGET_NAME... | SELECTOR...}",
+		Short: "run executes the selected targets in a single graph walk",
+		Long: `ark run src/go/services/my_service/build.ts goModules
+ark run //src/go/services/my_service/build.ts:goModules //src/go/services/other_service:image
+ark run //src/go/services/...:image
+
+Selectors starting with // are relative to the workspace root, other paths are relative to the current directory.
+A directory selects the build.ts file it contains and a path ending with ... selects every build.ts file below it.
+The target name may be a glob pattern, if it is omitted from a recursive selector every target is selected.
+
+ark run //src/go/... --label team=payments --exclude-label slow
+
+--label and --exclude-label filter the selected targets by label, a label without a value matches any value.`,
 		PreRunE: validateArgsRequired,
-		Args:    cobra.MinimumNArgs(2),
+		Args:    cobra.MinimumNArgs(1),
 		PersistentPreRunE: cobraRunEMiddleware(
 			ensureServerRunning(hostServerDaemon, logger),
 		),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			start := time.Now()
-			targetPath := args[0]
-			targetName := args[1]
 
 			defer func() { logger.Infof("executed in %s", time.Now().Sub(start)) }()
 
-			disconnectErrChan := make(chan error)
-			conn, err := nats.Connect("nats://127.0.0.1:4222", func(options *nats2.Options) error {
-				options.DisconnectedErrCB = func(conn *nats2.Conn, err error) {
-					disconnectErrChan <- errors.Wrap(err, "server broker disconnected")
-				}
-				return nil
-			})
-			if err != nil {
-				return err
-			}
-
-			broker := nats.NewBroker(conn)
-
 			cwd, err := os.Getwd()
 			if err != nil {
 				return nil
 			}
-			if !filepath.IsAbs(targetPath) {
-				targetPath, err = fs2.NormalizePath(cwd, targetPath)
-				if err != nil {
-					return err
-				}
+
+			selectorArgs := args
+			if cmd.ArgsLenAtDash() != -1 {
+				selectorArgs = args[:cmd.ArgsLenAtDash()]
 			}
 
-			logger.Infof("entrypoint %s", targetPath)
+			selectors, err := parseRunSelectors(selectorArgs, config.Root(), cwd)
+			if err != nil {
+				return err
+			}
 
 			async, err := cmd.Flags().GetBool("async")
 			if err != nil {
@@ -151,16 +154,42 @@ func newRunCmd(
 				return err
 			}
 
+			keepGoing, err := cmd.Flags().GetBool("keep-going")
+			if err != nil {
+				return err
+			}
+
 			skip, err := cmd.Flags().GetStringSlice("skip")
 			if err != nil {
 				return err
 			}
 
+			skipMode, err := cmd.Flags().GetString("skip-mode")
+			if err != nil {
+				return err
+			}
+
+			if _, err = graph.ParseSkipMode(skipMode); err != nil {
+				return err
+			}
+
 			ciMode, err := cmd.Flags().GetBool("ci")
 			if err != nil {
 				return err
 			}
 
+			includeLabels, err := cmd.Flags().GetStringSlice("label")
+			if err != nil {
+				return err
+			}
+
+			excludeLabels, err := cmd.Flags().GetStringSlice("exclude-label")
+			if err != nil {
+				return err
+			}
+
+			labels := ark.NewLabelSelector(includeLabels, excludeLabels)
+
 			if k8sContext != "" {
 				panic("--context is not implemented")
 			}
@@ -181,58 +210,91 @@ func newRunCmd(
 				return err
 			}
 
-			target := ark.RawTarget{
-				File:  targetPath,
-				Name:  targetName,
-				Realm: config.Root(),
+			logger.Infof("resolving workspace build files (this could take some time)")
+			resolved := make(map[string]bool)
+			for _, selector := range selectors {
+				buildFiles, buildFilesErr := selector.BuildFiles(ark.DefaultBuildFileName)
+				if buildFilesErr != nil {
+					return buildFilesErr
+				}
+				for _, buildFile := range buildFiles {
+					if resolved[buildFile] {
+						continue
+					}
+					resolved[buildFile] = true
+					logger.Infof("entrypoint %s", buildFile)
+					if _, err = vm.ResolveModule(buildFile); err != nil {
+						return err
+					}
+				}
 			}
+			logger.Infof("resolved in %s", time.Now().Sub(start))
 
-			logger.Infof("resolving workspace build files (this could take some time)")
-			if _, err = vm.ResolveModule(targetPath); err != nil {
+			targets, err := serverClient.GetTargets()
+			if err != nil {
 				return err
 			}
-			logger.Infof("resolved in %s", time.Now().Sub(start))
+
+			targetKeys, err := ark.SelectTargets(labels.Filter(targets), selectors)
+			if err != nil {
+				return err
+			}
+
+			for _, key := range targetKeys {
+				logger.Infof("selected %s", key)
+			}
 
 			if dryRun {
 				return nil
 			}
 
-			r, err := serverClient.Run(messages.GraphRunnerExecuteCommand{
-				TargetKeys:     []string{target.Key()},
+			command := messages.GraphRunnerExecuteCommand{
+				TargetKeys:     targetKeys,
+				Labels:         labels.Include,
+				ExcludeLabels:  labels.Exclude,
 				K8sNamespace:   k8sNamespace,
 				PushAfterBuild: push,
 				ForceBuild:     force,
+				KeepGoing:      keepGoing,
 				SkipFilters:    skip,
+				SkipMode:       skipMode,
 				MaxConcurrency: maxConcurrency,
 				K8sContext:     k8sContext,
-			})
-
-			if err != nil {
-				return err
 			}
 
-			logger.Infof("build ID %s", r.SubscriptionId)
-			logBuildLogURL(logger, r.SubscriptionId)
-
 			if async {
+				r, runErr := serverClient.Run(command)
+				if runErr != nil {
+					return runErr
+				}
+				logger.Infof("build ID %s", r.SubscriptionId)
+				logBuildLogURL(logger, r.SubscriptionId)
+				logger.Infof("resume watching the run with: ark run attach %s", r.SubscriptionId)
 				return nil
 			}
 
-			// subscribe to all events and filter events by subject
-			stream, err := broker.Subscribe(appcontext.Context(), ">", nil)
+			// the stream is not bound to the app context so an interrupt is handled by cancelling the run
+			eventsCtx, cancelEvents := context.WithCancel(context.Background())
+			defer cancelEvents()
+
+			id, stream, err := startRun(eventsCtx, serverClient, command)
+			if errors.Is(err, http_server.ErrRunFinished) {
+				return reportFinishedRun(cmd, serverClient, logger, id)
+			}
 			if err != nil {
 				return err
 			}
 
-			eg, _ := errgroup.WithContext(appcontext.Context())
+			logger.Infof("build ID %s", id)
+			logBuildLogURL(logger, id)
 
-			if term.IsTerminal(int(os.Stdout.Fd())) && !ciMode {
-				interactiveTUIMode(eg, stream, r, logger, broker)
-			} else {
-				fallbackRawOutputMode(eg, stream, r, logger, broker)
+			// the run is rebuilt from the events of this subscription to report on its timing
+			run := &ark.Run{
+				ID:             id,
+				MaxConcurrency: maxConcurrency,
 			}
 
-			return eg.Wait()
+			return followRun(cmd, serverClient, logger, run, stream, true)
 		},
 	}
 
@@ -240,31 +302,180 @@ func newRunCmd(
 	_ = runCmd.PersistentFlags().Bool("ci", false, "use this flag to disable interactive mode")
 	_ = runCmd.PersistentFlags().Bool("dry-run", false, "use this flag to load the action graph without execution")
 	_ = runCmd.PersistentFlags().Bool("force", false, "ignores cache and forces action action execution")
+	_ = runCmd.PersistentFlags().Bool("keep-going", false, "continues executing every target whose dependencies succeeded after a failure and reports every failed and blocked target at the end")
 	_ = runCmd.PersistentFlags().Bool("push", false, "pushes artifacts after successful actions (use for incremental CI builds)")
-	_ = runCmd.PersistentFlags().Bool("async", false, "returns the subscription id of the graph run to resume watching later")
-	_ = runCmd.PersistentFlags().StringSlice("skip", []string{}, "[DONT USE] supplies patterns to skip actions in the graph (useful for skipping tests, can cause unexpected behavior)")
+	_ = runCmd.PersistentFlags().Bool("async", false, "returns the subscription id of the graph run to resume watching later with ark run attach")
+	_ = runCmd.PersistentFlags().StringSlice("skip", []string{}, "glob patterns matched against target names and keys, skipped targets are not executed and their exclusive dependencies are pruned (e.g. *_test)")
+	_ = runCmd.PersistentFlags().String("skip-mode", string(graph.SkipModeFail), "how dependents of skipped targets are handled: fail refuses to run them, last-known runs them against the last built artifact of the skipped target and fails if it was never built")
+	_ = runCmd.PersistentFlags().Bool("summary", true, "prints the critical path, slowest targets and cache hit rate after the run")
+	_ = runCmd.PersistentFlags().Int("summary-top", run_report.DefaultTopN, "the number of slowest targets included in the summary")
+	_ = runCmd.PersistentFlags().StringSlice("label", []string{}, "only runs the selected targets that have every label (key or key=value)")
+	_ = runCmd.PersistentFlags().StringSlice("exclude-label", []string{}, "does not run the selected targets that have any of the labels (key or key=value)")
+	_ = runCmd.PersistentFlags().String("trace", "", "writes the run as Chrome trace event json to the given file")
 	_ = runCmd.PersistentFlags().IntP("max-concurrency", "m", runtime.GOMAXPROCS(0), "Sets a limit on the graph walk parallelism [default based on available CPUs]")
 
 	return runCmd
 }
 
+// startRun subscribes to the events of a run before the host server starts it so no event of a fast run is lost
+// the id of the run is chosen by the client because the subscription must exist before the run is published
+// http_server.ErrRunFinished is returned with the id of the run if the server reports it as finished
+func startRun(
+	ctx context.Context,
+	serverClient http_server.Client,
+	command messages.GraphRunnerExecuteCommand,
+) (string, <-chan cqrs.Envelope, error) {
+	command.RunID = uuid.New().String()
+
+	stream, streamErr := serverClient.GetRunEvents(ctx, command.RunID)
+	if streamErr != nil && !errors.Is(streamErr, http_server.ErrRunFinished) {
+		return "", nil, streamErr
+	}
+
+	r, err := serverClient.Run(command)
+	if err != nil {
+		return "", nil, err
+	}
+	return r.SubscriptionId, stream, streamErr
+}
+
+// reportFinishedRun reports a run that finished before its events could be followed
+// the error of the run is returned if it failed
+func reportFinishedRun(
+	cmd *cobra.Command,
+	serverClient http_server.Client,
+	logger logz.FieldLogger,
+	id string,
+) error {
+	run, err := serverClient.GetRun(id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get run %s", id)
+	}
+
+	logger.Infof("run %s already finished with status %s", id, run.Status)
+	if err = reportRun(cmd, serverClient, logger, run); err != nil {
+		return err
+	}
+	if run.Status == ark.RunStatusFailed {
+		return errors.Errorf("graph runner failed: %s", run.Error)
+	}
+	return nil
+}
+
+// followRun renders the progress of a run from its event stream and reports on its timing once the stream ends
+// run must hold the state of the run before the stream started, it is updated by every event
+// An interrupt cancels the run when cancelOnInterrupt is true, otherwise it only stops following the run
+func followRun(
+	cmd *cobra.Command,
+	serverClient http_server.Client,
+	logger logz.FieldLogger,
+	run *ark.Run,
+	stream <-chan cqrs.Envelope,
+	cancelOnInterrupt bool,
+) error {
+	ciMode, err := cmd.Flags().GetBool("ci")
+	if err != nil {
+		return err
+	}
+
+	eg, _ := errgroup.WithContext(appcontext.Context())
+
+	if term.IsTerminal(int(os.Stdout.Fd())) && !ciMode {
+		interactiveTUIMode(eg, stream, run.ID, logger, run, cancelOnInterrupt)
+	} else {
+		fallbackRawOutputMode(eg, stream, run.ID, logger, run, cancelOnInterrupt)
+	}
+
+	err = eg.Wait()
+
+	if reportErr := reportRun(cmd, serverClient, logger, *run); reportErr != nil {
+		return reportErr
+	}
+	return err
+}
+
+// reportRun prints the summary of a run and writes its trace as requested by the flags of the command
+func reportRun(
+	cmd *cobra.Command,
+	serverClient http_server.Client,
+	logger logz.FieldLogger,
+	run ark.Run,
+) error {
+	summary, err := cmd.Flags().GetBool("summary")
+	if err != nil {
+		return err
+	}
+
+	summaryTop, err := cmd.Flags().GetInt("summary-top")
+	if err != nil {
+		return err
+	}
+
+	traceFile, err := cmd.Flags().GetString("trace")
+	if err != nil {
+		return err
+	}
+
+	if summary {
+		edges, edgesErr := serverClient.GetGraphEdges()
+		if edgesErr != nil {
+			logger.Warnf("failed to compute the critical path %v", edgesErr)
+		}
+		printRunReport(run_report.New(run, edges, summaryTop))
+	}
+
+	if traceFile != "" {
+		if traceErr := writeRunTrace(traceFile, run); traceErr != nil {
+			logger.Warnf("failed to write trace %v", traceErr)
+		} else {
+			logger.Infof("trace written to %s", traceFile)
+		}
+	}
+	return nil
+}
+
+// onRunInterrupt cancels the run or detaches from it after the user interrupted the CLI
+func onRunInterrupt(id string, logger logz.FieldLogger, cancelOnInterrupt bool) error {
+	if !cancelOnInterrupt {
+		logger.Infof("detached from %s, the run continues on the host server", id)
+		return nil
+	}
+
+	logger.Infof("sending cancellation signal for %s", id)
+	return publishRunCancellation(id)
+}
+
 func fallbackRawOutputMode(
 	eg *errgroup.Group,
 	stream <-chan cqrs.Envelope,
-	r messages.GraphRunnerExecuteCommandResponse,
+	id string,
 	logger logz.FieldLogger,
-	broker cqrs.Broker,
+	run *ark.Run,
+	cancelOnInterrupt bool,
 ) {
 	eg.Go(func() error {
+		// the targets recorded before the stream started are logged with their current status
+		for _, target := range run.Targets {
+			hash := target.Hash
+			if len(hash) > 7 {
+				hash = hash[0:7]
+			}
+			logger.WithFields(logz.Fields{
+				"hash":   hash,
+				"target": target.Key,
+				"status": target.Status,
+			}).Info()
+		}
+
 		for {
 			select {
-			case err := <-natsDisconnected:
-				return err
-			case envelope := <-stream:
-				if envelope.Subject() != r.SubscriptionId {
-					continue
+			case envelope, ok := <-stream:
+				if !ok {
+					return errRunEventsLost
 				}
 
+				_ = run_recorder.Apply(run, envelope)
+
 				switch envelope.TypeKey() {
 				case events.GraphRunnerFailed:
 					return errors.Errorf("graph runner failed: %s", string(envelope.Data()))
@@ -283,14 +494,8 @@ func fallbackRawOutputMode(
 					"event":  envelope.Type(),
 				}).Info()
 			case <-appcontext.Context().Done():
-				logger.Infof("sending cancellation signal for %s", r.SubscriptionId)
-				pubErr := broker.Publish(topics.GraphRunnerCommands, cqrs.NewDefaultEnvelope(
-					commands.GraphRunnerCancelType,
-					cqrs.WithSource("arkcli"),
-					cqrs.WithSubject(cqrs.RouteKey(r.SubscriptionId)),
-				))
-				if pubErr != nil {
-					return errors.Wrap(pubErr, "failed to publish cancellation")
+				if err := onRunInterrupt(id, logger, cancelOnInterrupt); err != nil {
+					return err
 				}
 				return appcontext.Context().Err()
 			}
@@ -301,28 +506,34 @@ func fallbackRawOutputMode(
 func interactiveTUIMode(
 	eg *errgroup.Group,
 	stream <-chan cqrs.Envelope,
-	r messages.GraphRunnerExecuteCommandResponse,
+	id string,
 	logger logz.FieldLogger,
-	broker cqrs.Broker,
+	run *ark.Run,
+	cancelOnInterrupt bool,
 ) {
 	uiStream := make(chan tea.Msg, 1000)
 
+	// the targets recorded before the stream started are rendered before the events are applied
+	if len(run.Targets) > 0 {
+		uiStream <- graph_progress.Restore(*run)
+	}
+
 	eg.Go(func() error {
 		for {
 			select {
-			case err := <-natsDisconnected:
-				uiStream <- graph_progress.Stop()
-				return err
-			case envelope := <-stream:
-				if envelope.Subject() != r.SubscriptionId {
-					continue
+			case envelope, ok := <-stream:
+				if !ok {
+					uiStream <- graph_progress.Stop()
+					return errRunEventsLost
 				}
 
+				_ = run_recorder.Apply(run, envelope)
+
 				uiStream <- envelope
 				switch envelope.TypeKey() {
 				case events.GraphRunnerFailed:
 					uiStream <- graph_progress.Stop()
-					logBuildLogURL(logger, r.SubscriptionId)
+					logBuildLogURL(logger, id)
 
 					return errors.Errorf("graph runner failed: %s", string(envelope.Data()))
 				case events.GraphRunnerSuccess:
@@ -330,14 +541,8 @@ func interactiveTUIMode(
 					return nil
 				}
 			case <-appcontext.Context().Done():
-				logger.Infof("sending cancellation signal for %s", r.SubscriptionId)
-				pubErr := broker.Publish(topics.GraphRunnerCommands, cqrs.NewDefaultEnvelope(
-					commands.GraphRunnerCancelType,
-					cqrs.WithSource("arkcli"),
-					cqrs.WithSubject(cqrs.RouteKey(r.SubscriptionId)),
-				))
-				if pubErr != nil {
-					return errors.Wrap(pubErr, "failed to publish cancellation")
+				if err := onRunInterrupt(id, logger, cancelOnInterrupt); err != nil {
+					return err
 				}
 				select {
 				case uiStream <- graph_progress.Stop():
@@ -351,6 +556,28 @@ func interactiveTUIMode(
 	eg.Go(graph_progress.New(uiStream))
 }
 
+// publishRunCancellation publishes a cancellation command for a run to the broker of the host server
+func publishRunCancellation(id string) error {
+	conn, err := nats.Connect("nats://127.0.0.1:4222")
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to the server broker")
+	}
+
+	broker := nats.NewBroker(conn)
+	defer func() {
+		_ = broker.Close()
+	}()
+
+	if err = broker.Publish(topics.GraphRunnerCommands, cqrs.NewDefaultEnvelope(
+		commands.GraphRunnerCancelType,
+		cqrs.WithSource("arkcli"),
+		cqrs.WithSubject(cqrs.RouteKey(id)),
+	)); err != nil {
+		return errors.Wrap(err, "failed to publish cancellation")
+	}
+	return conn.Flush()
+}
+
 func ensureServerRunning(
 	daemon *daemonize.Proc,
 	logger logz.FieldLogger,
@@ -420,14 +647,59 @@ func InstallCLIModules(vm *typescript.VirtualMachine, cmd CMD, args []string, fl
 	})
 }
 
-func validateArgsRequired(_ *cobra.Command, args []string) error {
-	if len(args) < 1 {
-		return errors.New("TARGET_PATH is a required parameter")
+func validateArgsRequired(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 || cmd.ArgsLenAtDash() == 0 {
+		return errors.New("TARGET_PATH or SELECTOR is a required parameter")
 	}
 
 	return nil
 }
 
+// parseRunSelectors parses the targets of ark run
+// the arguments are either pairs of TARGET_PATH TARGET_NAME or selectors, the two forms can't be mixed
+func parseRunSelectors(args []string, realm, cwd string) ([]ark.TargetSelector, error) {
+	selectorForm := false
+	for _, arg := range args {
+		if strings.Contains(arg, ":") || strings.HasSuffix(arg, "...") {
+			selectorForm = true
+			break
+		}
+	}
+
+	var selectors []ark.TargetSelector
+	if !selectorForm {
+		if len(args)%2 != 0 {
+			return nil, errors.New("every TARGET_PATH must be followed by a TARGET_NAME")
+		}
+		for i := 0; i < len(args); i += 2 {
+			targetPath := args[i]
+			if !filepath.IsAbs(targetPath) {
+				var err error
+				if targetPath, err = fs2.NormalizePath(cwd, targetPath); err != nil {
+					return nil, err
+				}
+			}
+			selectors = append(selectors, ark.TargetSelector{
+				Path: targetPath,
+				Name: args[i+1],
+			})
+		}
+		return selectors, nil
+	}
+
+	for _, arg := range args {
+		if !strings.Contains(arg, ":") && !strings.HasSuffix(arg, "...") {
+			return nil, errors.Errorf("%s is not a selector, TARGET_PATH TARGET_NAME pairs can't be mixed with selectors", arg)
+		}
+		selector, err := ark.ParseTargetSelector(arg, realm, cwd)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
 type runE func(cmd *cobra.Command, args []string) error
 
 func cobraRunEMiddleware(middleware ...runE) runE {
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+func newRunAttachCmd(
+	runCmd *cobra.Command,
+	logger logz.FieldLogger,
+	serverClient http_server.Client,
+) *cobra.Command {
+	var runAttachCmd = &cobra.Command{
+		Use:   "attach RUN_ID",
+		Short: "attach resumes watching the progress of a run started with ark run --async",
+		Long: `ark run attach 6f1c9e4e-51a6-4f5e-9a55-5d3f2c1f8f4e
+
+The status of every target recorded by the host server is rendered before the events of the run are followed.
+Interrupting attach stops watching the run, use ark run cancel to cancel it.
+A run that already finished is reported without following its events.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("RUN_ID is a required parameter")
+			}
+			id := args[0]
+
+			// the events are subscribed to before the run is fetched so no event is lost in between
+			// an event that was already recorded is applied again which never moves a status backwards
+			eventsCtx, cancelEvents := context.WithCancel(context.Background())
+			defer cancelEvents()
+
+			stream, streamErr := serverClient.GetRunEvents(eventsCtx, id)
+			if streamErr != nil && !errors.Is(streamErr, http_server.ErrRunFinished) {
+				return streamErr
+			}
+
+			if streamErr != nil {
+				return reportFinishedRun(cmd, serverClient, logger, id)
+			}
+
+			run, err := serverClient.GetRun(id)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get run %s", id)
+			}
+
+			logger.Infof("attached to run %s", id)
+			logBuildLogURL(logger, id)
+
+			return followRun(cmd, serverClient, logger, &run, stream, false)
+		},
+	}
+
+	runCmd.AddCommand(runAttachCmd)
+	return runAttachCmd
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server"
+	"github.com/myfintech/ark/src/go/lib/logz"
+)
+
+func newRunCancelCmd(
+	runCmd *cobra.Command,
+	logger logz.FieldLogger,
+	serverClient http_server.Client,
+) *cobra.Command {
+	var runCancelCmd = &cobra.Command{
+		Use:   "cancel RUN_ID",
+		Short: "cancel stops a run executing on the host server",
+		Long: `ark run cancel 6f1c9e4e-51a6-4f5e-9a55-5d3f2c1f8f4e
+
+The graph runner cancels the context of the run, actions that are executing are interrupted
+and the run is recorded as failed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("RUN_ID is a required parameter")
+			}
+			id := args[0]
+
+			run, err := serverClient.GetRun(id)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get run %s", id)
+			}
+			if run.Status.Done() {
+				return errors.Errorf("run %s already finished with status %s", id, run.Status)
+			}
+
+			if err = publishRunCancellation(id); err != nil {
+				return err
+			}
+
+			logger.Infof("sent cancellation signal for %s", id)
+			return nil
+		},
+	}
+
+	runCmd.AddCommand(runCancelCmd)
+	return runCancelCmd
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cheynewallace/tabby"
+	"github.com/spf13/cobra"
+
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/run_report"
+)
+
+func newRunsCmd(rootCmd *cobra.Command) *cobra.Command {
+	var runsCmd = &cobra.Command{
+		Use:   "runs",
+		Short: "runs is a sub-command of ark that inspects the history of graph runs recorded by the host server",
+	}
+
+	rootCmd.AddCommand(runsCmd)
+	return runsCmd
+}
+
+// formatRunDuration formats the duration of a run or target, or "-" if it never finished
+func formatRunDuration(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// formatRunTime formats the time a run or target started, or "-" if it never started
+func formatRunTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+// printRunReport prints the timing summary of a run
+func printRunReport(report run_report.Report) {
+	maxConcurrency := "default"
+	if report.MaxConcurrency > 0 {
+		maxConcurrency = fmt.Sprint(report.MaxConcurrency)
+	}
+
+	summary := tabby.New()
+	summary.AddLine("wall_time", formatRunDuration(report.WallTime))
+	summary.AddLine("targets", fmt.Sprintf("%d (%d cached, %d skipped, %d executed, %d failed, %d failure allowed, %d unfinished)",
+		report.Targets, report.Cached, report.Skipped, report.Executed, report.Failed, report.FailureAllowed, report.Unfinished))
+	summary.AddLine("flaky", fmt.Sprint(report.Flaky))
+	summary.AddLine("blocked", fmt.Sprint(report.Blocked))
+	summary.AddLine("cache_hit_rate", fmt.Sprintf("%.1f%%", report.CacheHitRate()*100))
+	summary.AddLine("parallelism", fmt.Sprintf("%.2f (max concurrency %s)", report.Parallelism, maxConcurrency))
+	summary.AddLine("critical_path", formatRunDuration(report.CriticalPathDuration))
+	summary.Print()
+
+	criticalPath := tabby.New()
+	criticalPath.AddHeader("critical_path", "status", "duration")
+	for _, target := range report.CriticalPath {
+		criticalPath.AddLine(target.Key, target.Status, formatRunDuration(target.Duration()))
+	}
+	criticalPath.Print()
+
+	slowest := tabby.New()
+	slowest.AddHeader("slowest_targets", "status", "duration")
+	for _, target := range report.Slowest {
+		slowest.AddLine(target.Key, target.Status, formatRunDuration(target.Duration()))
+	}
+	slowest.Print()
+
+	if len(report.Failures) == 0 {
+		return
+	}
+
+	failures := tabby.New()
+	failures.AddHeader("failures", "status", "error")
+	for _, target := range report.Failures {
+		failures.AddLine(target.Key, target.Status, target.Error)
+	}
+	failures.Print()
+}
+
+// writeRunTrace writes the Chrome trace event json of a run to a file
+func writeRunTrace(path string, run ark.Run) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	if err = run_report.WriteTrace(file, run); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/cheynewallace/tabby"
+	"github.com/spf13/cobra"
+
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server"
+)
+
+func newRunsListCmd(
+	runsCmd *cobra.Command,
+	httpClient http_server.Client,
+) *cobra.Command {
+	var runsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "list is a sub-command of runs that lists the most recent graph runs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, err := cmd.Flags().GetInt("limit")
+			if err != nil {
+				return err
+			}
+
+			runs, err := httpClient.GetRuns()
+			if err != nil {
+				return err
+			}
+
+			if limit > 0 && len(runs) > limit {
+				runs = runs[:limit]
+			}
+
+			t := tabby.New()
+			t.AddHeader("run_id", "status", "started_at", "duration", "targets", "target_keys")
+			for _, run := range runs {
+				t.AddLine(
+					run.ID,
+					run.Status,
+					formatRunTime(run.StartedAt),
+					formatRunDuration(run.Duration()),
+					len(run.Targets),
+					strings.Join(run.TargetKeys, ","),
+				)
+			}
+			t.Print()
+			return nil
+		},
+	}
+
+	runsCmd.AddCommand(runsListCmd)
+	runsListCmd.Flags().Int("limit", 20, "the maximum number of runs to list (0 lists every run)")
+	return runsListCmd
+}
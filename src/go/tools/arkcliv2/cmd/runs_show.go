@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cheynewallace/tabby"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/myfintech/ark/src/go/lib/ark/run_report"
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server"
+)
+
+func newRunsShowCmd(
+	runsCmd *cobra.Command,
+	httpClient http_server.Client,
+) *cobra.Command {
+	var runsShowCmd = &cobra.Command{
+		Use:   "show RUN_ID",
+		Short: "show is a sub-command of runs that displays the status and timing of every target in a run",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("RUN_ID is a required parameter")
+			}
+
+			top, err := cmd.Flags().GetInt("top")
+			if err != nil {
+				return err
+			}
+
+			traceFile, err := cmd.Flags().GetString("trace")
+			if err != nil {
+				return err
+			}
+
+			run, err := httpClient.GetRun(args[0])
+			if err != nil {
+				return err
+			}
+
+			edges, err := httpClient.GetGraphEdges()
+			if err != nil {
+				return err
+			}
+
+			summary := tabby.New()
+			summary.AddLine("run_id", run.ID)
+			summary.AddLine("status", run.Status)
+			summary.AddLine("target_keys", strings.Join(run.TargetKeys, ","))
+			summary.AddLine("started_at", formatRunTime(run.StartedAt))
+			summary.AddLine("duration", formatRunDuration(run.Duration()))
+			if run.Error != "" {
+				summary.AddLine("error", run.Error)
+			}
+			summary.Print()
+
+			targets := run.Targets
+			sort.SliceStable(targets, func(i, j int) bool {
+				return targets[i].QueuedAt.Before(targets[j].QueuedAt)
+			})
+
+			t := tabby.New()
+			t.AddHeader("target_key", "hash", "status", "cached", "duration", "error")
+			for _, target := range targets {
+				hash := target.Hash
+				if len(hash) > 7 {
+					hash = hash[0:7]
+				}
+				t.AddLine(
+					target.Key,
+					hash,
+					target.Status,
+					target.Cached(),
+					formatRunDuration(target.Duration()),
+					target.Error,
+				)
+			}
+			t.Print()
+
+			printRunReport(run_report.New(run, edges, top))
+
+			if traceFile != "" {
+				return writeRunTrace(traceFile, run)
+			}
+			return nil
+		},
+	}
+
+	runsCmd.AddCommand(runsShowCmd)
+	runsShowCmd.Flags().Int("top", run_report.DefaultTopN, "the number of slowest targets to report")
+	runsShowCmd.Flags().String("trace", "", "writes the run as Chrome trace event json to the given file")
+	return runsShowCmd
+}
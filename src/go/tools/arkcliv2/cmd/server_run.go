@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"github.com/moby/buildkit/util/appcontext"
+	"github.com/pkg/errors"
+
 	"github.com/myfintech/ark/src/go/lib/ark"
 	"github.com/myfintech/ark/src/go/lib/ark/shared_clients"
+	"github.com/myfintech/ark/src/go/lib/ark/storage/sqlite"
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems"
 	"github.com/myfintech/ark/src/go/lib/ark/workspace"
 	"github.com/myfintech/ark/src/go/lib/logz"
@@ -19,6 +22,7 @@ import (
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems/k8s_echo"
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems/live_sync"
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems/port_binder"
+	"github.com/myfintech/ark/src/go/lib/ark/subsystems/run_recorder"
 	"github.com/myfintech/ark/src/go/lib/fs/observer"
 )
 
@@ -44,13 +48,36 @@ func newServerRunCmd(
 			if err != nil {
 				return err
 			}
+			if !cmd.Flags().Changed("broker") && config.Broker.Type != "" {
+				brokerType = config.Broker.Type
+			}
 
 			brokerAddress, err := cmd.Flags().GetString("broker-address")
 			if err != nil {
 				return err
 			}
 
-			natsd, err := nats.RunServer(nil)
+			storeDriver, err := cmd.Flags().GetString("store")
+			if err != nil {
+				return err
+			}
+
+			storePath, err := cmd.Flags().GetString("store-path")
+			if err != nil {
+				return err
+			}
+
+			store, err = openStore(store, storeDriver, storePath, *config)
+			if err != nil {
+				return err
+			}
+
+			natsOptions, err := embedded_broker.ServerOptions(brokerType)
+			if err != nil {
+				return err
+			}
+
+			natsd, err := nats.RunServer(&natsOptions)
 			if err != nil {
 				return err
 			}
@@ -77,7 +104,10 @@ func newServerRunCmd(
 				fsStream = fileObserver.FileSystemStream.Observe()
 			}
 
-			broker := nats.NewBroker(conn)
+			broker, err := embedded_broker.NewBroker(brokerType, conn)
+			if err != nil {
+				return err
+			}
 			sharedClients.Broker = broker
 			liveSyncConnectionManager := live_sync.NewConnectionManager(appcontext.Context())
 
@@ -86,16 +116,21 @@ func newServerRunCmd(
 				return err
 			}
 
+			recorder := run_recorder.NewRecorder(store)
+			forwards := port_binder.NewForwards()
+
 			if err = subsystemsManager.Register(
-				http_server.NewSubsystem(addr, logFilePath, store, logger, broker),
+				http_server.NewSubsystem(addr, logFilePath, recorder.Store(store), logger, broker, forwards),
 				graph_runner.NewSubsystem(store, logger, *sharedClients, broker),
 				embedded_broker.NewSubsystem(brokerType, brokerAddress, logger, natsd, broker),
 				fs_observer.NewSubsystem(logger, broker, fsStream),
-				port_binder.NewSubsystem(broker, logger, sharedClients.K8s),
-				port_binder.K8sEchoHandler(broker, logger, sharedClients.K8s),
+				port_binder.NewSubsystem(broker, logger, sharedClients.K8s, forwards),
+				port_binder.K8sEchoHandler(broker, logger, sharedClients.K8s, forwards),
 				live_sync.NewConnectionManagerSubsystem(broker, logger, liveSyncConnectionManager),
 				live_sync.NewFSSync(broker, logger, liveSyncConnectionManager, *config),
 				k8s_echo.NewSubsystem(broker, logger, sharedClients.K8s, *config),
+				run_recorder.NewSubsystem(recorder, logger, broker),
+				run_recorder.GraphRunnerHandler(recorder, logger, broker),
 			); err != nil {
 				return err
 			}
@@ -112,5 +147,45 @@ func newServerRunCmd(
 		StringP("address", "a", "127.0.0.1:9000", "The address for the server to listen on")
 	serverRunCmd.Flags().
 		Bool("disable-live-sync", false, "Disables the live sync system and fs observation (use in CI)")
+	serverRunCmd.Flags().
+		String("store", "", "The storage backend used to persist the target graph (memory|sqlite) [default from workspace config or memory]")
+	serverRunCmd.Flags().
+		String("store-path", "", "The path to the sqlite database file [default $ARK_DATA_HOME/store.db]")
 	return serverRunCmd
 }
+
+// openStore resolves the storage backend of the host server
+// the flag values take precedence over the workspace configuration
+// the memory store is returned when no driver is configured
+func openStore(memoryStore ark.Store, driver, path string, config workspace.Config) (ark.Store, error) {
+	if driver == "" {
+		driver = config.Storage.Driver
+	}
+	if path == "" {
+		path = config.Storage.Path
+	}
+
+	switch driver {
+	case "", "memory":
+		return memoryStore, nil
+	case "sqlite":
+		if path == "" {
+			defaultPath, err := sqlite.DefaultConnection()
+			if err != nil {
+				return nil, err
+			}
+			path = defaultPath
+		}
+
+		store := new(sqlite.Store)
+		if err := store.Open(path); err != nil {
+			return nil, errors.Wrapf(err, "failed to open sqlite store %s", path)
+		}
+		if err := store.Migrate(); err != nil {
+			return nil, errors.Wrapf(err, "failed to migrate sqlite store %s", path)
+		}
+		return store, nil
+	default:
+		return nil, errors.Errorf("%s is not a valid storage driver", driver)
+	}
+}
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/cheynewallace/tabby"
+	"github.com/myfintech/ark/src/go/lib/ark"
 	"github.com/myfintech/ark/src/go/lib/ark/subsystems/http_server"
 	"github.com/spf13/cobra"
 )
@@ -14,16 +17,26 @@ func newTargetsListCmd(
 		Use:   "list",
 		Short: "list is a sub-command of targets that lists all buildable targets",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			includeLabels, err := cmd.Flags().GetStringSlice("label")
+			if err != nil {
+				return err
+			}
+
+			excludeLabels, err := cmd.Flags().GetStringSlice("exclude-label")
+			if err != nil {
+				return err
+			}
+
 			t := tabby.New()
-			t.AddHeader("target_key")
+			t.AddHeader("target_key", "labels")
 
-			targets, err := httpClient.GetTargets()
+			targets, err := httpClient.GetTargetsByLabels(ark.NewLabelSelector(includeLabels, excludeLabels))
 			if err != nil {
 				return err
 			}
 
 			for _, target := range targets {
-				t.AddLine(target.Key())
+				t.AddLine(target.Key(), strings.Join(target.Labels, ","))
 			}
 
 			t.Print()
@@ -32,5 +45,7 @@ func newTargetsListCmd(
 	}
 
 	targetsCmd.AddCommand(targetsListCmd)
+	targetsListCmd.Flags().StringSlice("label", []string{}, "only lists targets that have every label (key or key=value)")
+	targetsListCmd.Flags().StringSlice("exclude-label", []string{}, "does not list targets that have any of the labels (key or key=value)")
 	return targetsListCmd
 }
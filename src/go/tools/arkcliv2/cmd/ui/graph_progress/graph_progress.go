@@ -26,6 +26,10 @@ const (
 	cached
 	failed
 	deployed
+	skipped
+	failureAllowed
+	blocked
+	awaiting
 )
 
 var (
@@ -57,6 +61,14 @@ func status(state state) string {
 		return "✅"
 	case deployed:
 		return "🚀"
+	case skipped:
+		return "⏭️"
+	case failureAllowed:
+		return "⚠️"
+	case blocked:
+		return "⛔"
+	case awaiting:
+		return "⏳"
 	default:
 		return ""
 	}
@@ -75,6 +87,37 @@ type stop struct{}
 func Stop() stop {
 	return stop{}
 }
+
+type restore struct {
+	run ark.Run
+}
+
+// Restore renders the targets of a run recorded by the host server before its events are streamed
+func Restore(run ark.Run) restore {
+	return restore{run: run}
+}
+
+// restoredState maps the recorded status of a target to its state
+func restoredState(status ark.RunStatus) state {
+	switch status {
+	case ark.RunStatusRunning:
+		return running
+	case ark.RunStatusCached:
+		return cached
+	case ark.RunStatusSkipped:
+		return skipped
+	case ark.RunStatusSuccess:
+		return complete
+	case ark.RunStatusFailed:
+		return failed
+	case ark.RunStatusFailureAllowed:
+		return failureAllowed
+	case ark.RunStatusBlocked:
+		return blocked
+	default:
+		return queued
+	}
+}
 func nextEventInStream(stream <-chan tea.Msg) tea.Cmd {
 	return func() tea.Msg {
 		return <-stream
@@ -154,6 +197,47 @@ func (m *GraphRenderModel) advanceSpinnerOnTick(msg tea.Msg) tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+func (m *GraphRenderModel) onRestore(msg tea.Msg) tea.Cmd {
+	cm, ok := msg.(restore)
+	if !ok {
+		return nil
+	}
+
+	for _, recorded := range cm.run.Targets {
+		if _, exists := m.TargetIdx[recorded.Key]; exists {
+			continue
+		}
+
+		target := &TargetModel{
+			state:         restoredState(recorded.Status),
+			name:          recorded.Key,
+			hash:          recorded.Hash,
+			startTime:     recorded.QueuedAt,
+			lastEventTime: recorded.FinishedAt,
+			lastEvent:     cqrs.RouteKey(recorded.Status),
+			spinner: spinner.Model{
+				Spinner: spinner.MiniDot,
+			},
+		}
+		if target.startTime.IsZero() {
+			target.startTime = time.Now()
+		}
+		if target.lastEventTime.Before(target.startTime) {
+			target.lastEventTime = target.startTime
+		}
+		if recorded.Status.Done() {
+			target.spinner.Finish()
+			target.hideSpinner = true
+		}
+
+		m.Targets = append(m.Targets, target)
+		m.TargetIdx[recorded.Key] = target
+	}
+
+	m.viewport.GotoBottom()
+	return spinner.Tick
+}
+
 func (m *GraphRenderModel) onCQRSEnvelope(msg tea.Msg) tea.Cmd {
 	var (
 		cmd  tea.Cmd
@@ -170,7 +254,10 @@ func (m *GraphRenderModel) onCQRSEnvelope(msg tea.Msg) tea.Cmd {
 			break
 		}
 
-		if routeKey == events.GraphWalkerDerivationComputed {
+		// blocked targets are never derived by the walker so they are added when they are reported
+		// a target restored from the recorded run is not added again when its events are replayed
+		_, exists := m.TargetIdx[d.RawTarget.Key()]
+		if !exists && (routeKey == events.GraphWalkerDerivationComputed || routeKey == events.GraphWalkerActionBlocked) {
 			target := &TargetModel{
 				state:     queued,
 				name:      d.RawTarget.Key(),
@@ -203,6 +290,7 @@ func (m GraphRenderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	cmds = append(cmds, m.quitOnInterrupt(msg))
 	cmds = append(cmds, m.advanceSpinnerOnTick(msg))
+	cmds = append(cmds, m.onRestore(msg))
 	cmds = append(cmds, m.onCQRSEnvelope(msg))
 	cmds = append(cmds, m.updateViewport(msg))
 
@@ -239,7 +327,7 @@ func (t TargetModel) Init() tea.Cmd {
 
 func (t *TargetModel) updateTime() {
 	switch t.state {
-	case cached, deployed, complete, failed:
+	case cached, deployed, complete, failed, skipped, failureAllowed, blocked:
 		return
 	default:
 		t.lastEventTime = time.Now()
@@ -272,11 +360,29 @@ func (t *TargetModel) updateByCQRSEnvelope(envelop cqrs.Envelope) (tea.Model, te
 	case events.GraphWalkerActionStarted:
 		t.state = running
 		return t, nil
+	case events.GraphWalkerActionAwaitingShared:
+		t.state = awaiting
+		return t, nil
 	case events.GraphWalkerActionCached:
 		t.state = cached
 		t.spinner.Finish()
 		t.hideSpinner = true
 		return t, nil
+	case events.GraphWalkerActionSkipped:
+		t.state = skipped
+		t.spinner.Finish()
+		t.hideSpinner = true
+		return t, nil
+	case events.GraphWalkerActionBlocked:
+		t.state = blocked
+		t.spinner.Finish()
+		t.hideSpinner = true
+		return t, nil
+	case events.GraphWalkerActionFailureAllowed:
+		t.state = failureAllowed
+		t.spinner.Finish()
+		t.hideSpinner = true
+		return t, nil
 	case events.GraphWalkerActionSuccess:
 		t.state = complete
 		t.spinner.Finish()
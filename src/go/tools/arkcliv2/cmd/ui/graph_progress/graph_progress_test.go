@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"testing"
 
+	"github.com/myfintech/ark/src/go/lib/ark"
+	"github.com/myfintech/ark/src/go/lib/ark/cqrs"
 	"github.com/myfintech/ark/src/go/lib/ark/cqrs/events"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -27,3 +29,36 @@ func TestTargetModel(t *testing.T) {
 	require.Implements(t, (*tea.Model)(nil), model)
 	t.Log(model.View())
 }
+
+func TestGraphRenderModel_Restore(t *testing.T) {
+	var model tea.Model = &GraphRenderModel{
+		Stream:    make(chan tea.Msg),
+		Targets:   make([]*TargetModel, 0),
+		TargetIdx: make(map[string]*TargetModel),
+	}
+
+	model, _ = model.Update(Restore(ark.Run{
+		ID: "run-1",
+		Targets: ark.RunTargets{
+			{Key: "lib/build.ts:lib", Hash: "aaa", Status: ark.RunStatusSuccess},
+			{Key: "app/build.ts:image", Hash: "bbb", Status: ark.RunStatusRunning},
+		},
+	}))
+
+	restored := model.(GraphRenderModel)
+	require.Len(t, restored.Targets, 2)
+	require.Equal(t, complete, restored.TargetIdx["lib/build.ts:lib"].state)
+	require.Equal(t, running, restored.TargetIdx["app/build.ts:image"].state)
+
+	// a replayed derivation of a restored target must not add it twice
+	envelope := cqrs.NewDefaultEnvelope(
+		events.GraphWalkerDerivationComputedType,
+		cqrs.WithData(cqrs.ApplicationJSON, ark.Derivative{
+			RawTarget: ark.RawTarget{Name: "lib", File: "/realm/lib/build.ts", Realm: "/realm"},
+		}),
+	)
+	require.NoError(t, envelope.Error)
+
+	model, _ = model.Update(envelope)
+	require.Len(t, model.(GraphRenderModel).Targets, 2)
+}